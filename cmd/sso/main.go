@@ -1,6 +1,7 @@
 package main
 
 import (
+	stdlog "log"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -14,32 +15,72 @@ import (
 func main() {
 	cfg := config.MustLoad("")
 
-	log := logger.InitLogger(cfg.Env)
+	log, logLevel, err := logger.New(cfg.Env, logger.Options{
+		Level:     cfg.Log.Level,
+		Format:    cfg.Log.Format,
+		Output:    cfg.Log.Output,
+		AddSource: cfg.Log.AddSource,
+		File: logger.FileOptions{
+			Path:       cfg.Log.File.Path,
+			MaxSize:    cfg.Log.File.MaxSize,
+			MaxBackups: cfg.Log.File.MaxBackups,
+			MaxAge:     cfg.Log.File.MaxAge,
+			Compress:   cfg.Log.File.Compress,
+		},
+		Syslog: logger.SyslogOptions{
+			Network: cfg.Log.Syslog.Network,
+			Address: cfg.Log.Syslog.Address,
+			Tag:     cfg.Log.Syslog.Tag,
+		},
+		RedactKeys: cfg.Log.RedactKeys,
+	})
+	if err != nil {
+		stdlog.Fatalf("failed to init logger: %s", err.Error())
+	}
 
-	log.Info("Starting sso service", slog.String("env", cfg.Env))
+	log.Info("Starting sso service", slog.String("env", cfg.Env), slog.String("log_level", logLevel.Level().String()))
 	log.Debug("Tokens TTL", slog.Duration("access_token_ttl", cfg.AccessTokenTtl), slog.Duration("refresh_token_ttl", cfg.RefreshTokenTtl))
 	log.Debug("GRPC Configuration", slog.String("host", cfg.GRPC.Host), slog.Int("port", cfg.GRPC.Port), slog.Duration("timeout", cfg.GRPC.Timeout))
 	log.Debug("Postgres Configuration", slog.String("host", cfg.Postgres.Host), slog.Int("port", cfg.Postgres.Port), slog.String("database", cfg.Postgres.Database))
 
-	application, err := app.New(
-		log,
-		cfg.Postgres.DSNTemplate,
-		cfg.GRPC.Port,
-		cfg.AccessTokenTtl,
-		cfg.RefreshTokenTtl,
-	)
+	application, err := app.New(log, cfg, logLevel)
 	if err != nil {
 		log.Error("failed to initialize application", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	go application.GRPCApp.MustRun()
+	runErr := make(chan error, 6)
+	go func() { runErr <- application.GRPCApp.Run() }()
+	if application.MetricsApp != nil {
+		go func() { runErr <- application.MetricsApp.Run() }()
+	}
+	if application.GatewayApp != nil {
+		go func() { runErr <- application.GatewayApp.Run() }()
+	}
+	if application.WebApp != nil {
+		go func() { runErr <- application.WebApp.Run() }()
+	}
+	if application.OAuthApp != nil {
+		go func() { runErr <- application.OAuthApp.Run() }()
+	}
+	if application.DebugApp != nil {
+		go func() { runErr <- application.DebugApp.Run() }()
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
-	sign := <-stop
-	log.Info("Stopping sso service", slog.String("signal", sign.String()))
-	application.GRPCApp.Stop()
+	select {
+	case sign := <-stop:
+		log.Info("Stopping sso service", slog.String("signal", sign.String()))
+	case err := <-runErr:
+		if err != nil {
+			log.Error("server failed, stopping sso service", slog.String("error", err.Error()))
+			application.Stop()
+			os.Exit(1)
+		}
+	}
+
+	application.Stop()
 	log.Info("sso service stopped")
 }
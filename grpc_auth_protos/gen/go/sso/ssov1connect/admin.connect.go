@@ -0,0 +1,582 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: sso/admin.proto
+
+package ssov1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	sso "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// AdminName is the fully-qualified name of the Admin service.
+	AdminName = "github.chaykovski.auth.Admin"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AdminListAuditEventsProcedure is the fully-qualified name of the Admin's ListAuditEvents RPC.
+	AdminListAuditEventsProcedure = "/github.chaykovski.auth.Admin/ListAuditEvents"
+	// AdminGetLogLevelProcedure is the fully-qualified name of the Admin's GetLogLevel RPC.
+	AdminGetLogLevelProcedure = "/github.chaykovski.auth.Admin/GetLogLevel"
+	// AdminSetLogLevelProcedure is the fully-qualified name of the Admin's SetLogLevel RPC.
+	AdminSetLogLevelProcedure = "/github.chaykovski.auth.Admin/SetLogLevel"
+	// AdminGrantAdminProcedure is the fully-qualified name of the Admin's GrantAdmin RPC.
+	AdminGrantAdminProcedure = "/github.chaykovski.auth.Admin/GrantAdmin"
+	// AdminRevokeAdminProcedure is the fully-qualified name of the Admin's RevokeAdmin RPC.
+	AdminRevokeAdminProcedure = "/github.chaykovski.auth.Admin/RevokeAdmin"
+	// AdminGrantRoleProcedure is the fully-qualified name of the Admin's GrantRole RPC.
+	AdminGrantRoleProcedure = "/github.chaykovski.auth.Admin/GrantRole"
+	// AdminRevokeRoleProcedure is the fully-qualified name of the Admin's RevokeRole RPC.
+	AdminRevokeRoleProcedure = "/github.chaykovski.auth.Admin/RevokeRole"
+	// AdminPutPolicyProcedure is the fully-qualified name of the Admin's PutPolicy RPC.
+	AdminPutPolicyProcedure = "/github.chaykovski.auth.Admin/PutPolicy"
+	// AdminListPoliciesProcedure is the fully-qualified name of the Admin's ListPolicies RPC.
+	AdminListPoliciesProcedure = "/github.chaykovski.auth.Admin/ListPolicies"
+	// AdminCreateAppProcedure is the fully-qualified name of the Admin's CreateApp RPC.
+	AdminCreateAppProcedure = "/github.chaykovski.auth.Admin/CreateApp"
+	// AdminUpdateAppProcedure is the fully-qualified name of the Admin's UpdateApp RPC.
+	AdminUpdateAppProcedure = "/github.chaykovski.auth.Admin/UpdateApp"
+	// AdminListAppsProcedure is the fully-qualified name of the Admin's ListApps RPC.
+	AdminListAppsProcedure = "/github.chaykovski.auth.Admin/ListApps"
+	// AdminDeleteAppProcedure is the fully-qualified name of the Admin's DeleteApp RPC.
+	AdminDeleteAppProcedure = "/github.chaykovski.auth.Admin/DeleteApp"
+	// AdminRotateAppSecretProcedure is the fully-qualified name of the Admin's RotateAppSecret RPC.
+	AdminRotateAppSecretProcedure = "/github.chaykovski.auth.Admin/RotateAppSecret"
+	// AdminAddAppMemberProcedure is the fully-qualified name of the Admin's AddAppMember RPC.
+	AdminAddAppMemberProcedure = "/github.chaykovski.auth.Admin/AddAppMember"
+	// AdminRemoveAppMemberProcedure is the fully-qualified name of the Admin's RemoveAppMember RPC.
+	AdminRemoveAppMemberProcedure = "/github.chaykovski.auth.Admin/RemoveAppMember"
+	// AdminListAppMembersProcedure is the fully-qualified name of the Admin's ListAppMembers RPC.
+	AdminListAppMembersProcedure = "/github.chaykovski.auth.Admin/ListAppMembers"
+)
+
+// AdminClient is a client for the github.chaykovski.auth.Admin service.
+type AdminClient interface {
+	ListAuditEvents(context.Context, *connect.Request[sso.ListAuditEventsRequest]) (*connect.Response[sso.ListAuditEventsResponse], error)
+	GetLogLevel(context.Context, *connect.Request[sso.GetLogLevelRequest]) (*connect.Response[sso.GetLogLevelResponse], error)
+	SetLogLevel(context.Context, *connect.Request[sso.SetLogLevelRequest]) (*connect.Response[sso.SetLogLevelResponse], error)
+	// GrantAdmin grants admin role to user globally, caller must be admin
+	GrantAdmin(context.Context, *connect.Request[sso.GrantAdminRequest]) (*connect.Response[sso.GrantAdminResponse], error)
+	// RevokeAdmin revokes global admin role of user, the last admin can't be revoked
+	RevokeAdmin(context.Context, *connect.Request[sso.RevokeAdminRequest]) (*connect.Response[sso.RevokeAdminResponse], error)
+	// GrantRole grants role to user globally or in the app, caller must be admin
+	GrantRole(context.Context, *connect.Request[sso.GrantRoleRequest]) (*connect.Response[sso.GrantRoleResponse], error)
+	// RevokeRole revokes role of user granted globally or in the app, caller must be admin
+	RevokeRole(context.Context, *connect.Request[sso.RevokeRoleRequest]) (*connect.Response[sso.RevokeRoleResponse], error)
+	// PutPolicy saves new version of policy, the latest version of policy is effective
+	PutPolicy(context.Context, *connect.Request[sso.PutPolicyRequest]) (*connect.Response[sso.PutPolicyResponse], error)
+	// ListPolicies returns the latest versions of all policies or all versions of policy
+	ListPolicies(context.Context, *connect.Request[sso.ListPoliciesRequest]) (*connect.Response[sso.ListPoliciesResponse], error)
+	// CreateApp registers app with generated client id and secret, the secret is returned only once
+	CreateApp(context.Context, *connect.Request[sso.CreateAppRequest]) (*connect.Response[sso.CreateAppResponse], error)
+	// UpdateApp replaces settings of app, disabled app can't be used to log in but keeps its data
+	UpdateApp(context.Context, *connect.Request[sso.UpdateAppRequest]) (*connect.Response[sso.UpdateAppResponse], error)
+	ListApps(context.Context, *connect.Request[sso.ListAppsRequest]) (*connect.Response[sso.ListAppsResponse], error)
+	// DeleteApp deletes app with its sessions, memberships, role assignments and policies
+	DeleteApp(context.Context, *connect.Request[sso.DeleteAppRequest]) (*connect.Response[sso.DeleteAppResponse], error)
+	// RotateAppSecret replaces client secret of app, the new secret is returned only once
+	RotateAppSecret(context.Context, *connect.Request[sso.RotateAppSecretRequest]) (*connect.Response[sso.RotateAppSecretResponse], error)
+	// AddAppMember lets user log in to app requiring membership
+	AddAppMember(context.Context, *connect.Request[sso.AddAppMemberRequest]) (*connect.Response[sso.AddAppMemberResponse], error)
+	// RemoveAppMember removes user from members of app, sessions of the user in the app can't be refreshed
+	// if the app requires membership
+	RemoveAppMember(context.Context, *connect.Request[sso.RemoveAppMemberRequest]) (*connect.Response[sso.RemoveAppMemberResponse], error)
+	ListAppMembers(context.Context, *connect.Request[sso.ListAppMembersRequest]) (*connect.Response[sso.ListAppMembersResponse], error)
+}
+
+// NewAdminClient constructs a client for the github.chaykovski.auth.Admin service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminMethods := sso.File_sso_admin_proto.Services().ByName("Admin").Methods()
+	return &adminClient{
+		listAuditEvents: connect.NewClient[sso.ListAuditEventsRequest, sso.ListAuditEventsResponse](
+			httpClient,
+			baseURL+AdminListAuditEventsProcedure,
+			connect.WithSchema(adminMethods.ByName("ListAuditEvents")),
+			connect.WithClientOptions(opts...),
+		),
+		getLogLevel: connect.NewClient[sso.GetLogLevelRequest, sso.GetLogLevelResponse](
+			httpClient,
+			baseURL+AdminGetLogLevelProcedure,
+			connect.WithSchema(adminMethods.ByName("GetLogLevel")),
+			connect.WithClientOptions(opts...),
+		),
+		setLogLevel: connect.NewClient[sso.SetLogLevelRequest, sso.SetLogLevelResponse](
+			httpClient,
+			baseURL+AdminSetLogLevelProcedure,
+			connect.WithSchema(adminMethods.ByName("SetLogLevel")),
+			connect.WithClientOptions(opts...),
+		),
+		grantAdmin: connect.NewClient[sso.GrantAdminRequest, sso.GrantAdminResponse](
+			httpClient,
+			baseURL+AdminGrantAdminProcedure,
+			connect.WithSchema(adminMethods.ByName("GrantAdmin")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeAdmin: connect.NewClient[sso.RevokeAdminRequest, sso.RevokeAdminResponse](
+			httpClient,
+			baseURL+AdminRevokeAdminProcedure,
+			connect.WithSchema(adminMethods.ByName("RevokeAdmin")),
+			connect.WithClientOptions(opts...),
+		),
+		grantRole: connect.NewClient[sso.GrantRoleRequest, sso.GrantRoleResponse](
+			httpClient,
+			baseURL+AdminGrantRoleProcedure,
+			connect.WithSchema(adminMethods.ByName("GrantRole")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeRole: connect.NewClient[sso.RevokeRoleRequest, sso.RevokeRoleResponse](
+			httpClient,
+			baseURL+AdminRevokeRoleProcedure,
+			connect.WithSchema(adminMethods.ByName("RevokeRole")),
+			connect.WithClientOptions(opts...),
+		),
+		putPolicy: connect.NewClient[sso.PutPolicyRequest, sso.PutPolicyResponse](
+			httpClient,
+			baseURL+AdminPutPolicyProcedure,
+			connect.WithSchema(adminMethods.ByName("PutPolicy")),
+			connect.WithClientOptions(opts...),
+		),
+		listPolicies: connect.NewClient[sso.ListPoliciesRequest, sso.ListPoliciesResponse](
+			httpClient,
+			baseURL+AdminListPoliciesProcedure,
+			connect.WithSchema(adminMethods.ByName("ListPolicies")),
+			connect.WithClientOptions(opts...),
+		),
+		createApp: connect.NewClient[sso.CreateAppRequest, sso.CreateAppResponse](
+			httpClient,
+			baseURL+AdminCreateAppProcedure,
+			connect.WithSchema(adminMethods.ByName("CreateApp")),
+			connect.WithClientOptions(opts...),
+		),
+		updateApp: connect.NewClient[sso.UpdateAppRequest, sso.UpdateAppResponse](
+			httpClient,
+			baseURL+AdminUpdateAppProcedure,
+			connect.WithSchema(adminMethods.ByName("UpdateApp")),
+			connect.WithClientOptions(opts...),
+		),
+		listApps: connect.NewClient[sso.ListAppsRequest, sso.ListAppsResponse](
+			httpClient,
+			baseURL+AdminListAppsProcedure,
+			connect.WithSchema(adminMethods.ByName("ListApps")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteApp: connect.NewClient[sso.DeleteAppRequest, sso.DeleteAppResponse](
+			httpClient,
+			baseURL+AdminDeleteAppProcedure,
+			connect.WithSchema(adminMethods.ByName("DeleteApp")),
+			connect.WithClientOptions(opts...),
+		),
+		rotateAppSecret: connect.NewClient[sso.RotateAppSecretRequest, sso.RotateAppSecretResponse](
+			httpClient,
+			baseURL+AdminRotateAppSecretProcedure,
+			connect.WithSchema(adminMethods.ByName("RotateAppSecret")),
+			connect.WithClientOptions(opts...),
+		),
+		addAppMember: connect.NewClient[sso.AddAppMemberRequest, sso.AddAppMemberResponse](
+			httpClient,
+			baseURL+AdminAddAppMemberProcedure,
+			connect.WithSchema(adminMethods.ByName("AddAppMember")),
+			connect.WithClientOptions(opts...),
+		),
+		removeAppMember: connect.NewClient[sso.RemoveAppMemberRequest, sso.RemoveAppMemberResponse](
+			httpClient,
+			baseURL+AdminRemoveAppMemberProcedure,
+			connect.WithSchema(adminMethods.ByName("RemoveAppMember")),
+			connect.WithClientOptions(opts...),
+		),
+		listAppMembers: connect.NewClient[sso.ListAppMembersRequest, sso.ListAppMembersResponse](
+			httpClient,
+			baseURL+AdminListAppMembersProcedure,
+			connect.WithSchema(adminMethods.ByName("ListAppMembers")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminClient implements AdminClient.
+type adminClient struct {
+	listAuditEvents *connect.Client[sso.ListAuditEventsRequest, sso.ListAuditEventsResponse]
+	getLogLevel     *connect.Client[sso.GetLogLevelRequest, sso.GetLogLevelResponse]
+	setLogLevel     *connect.Client[sso.SetLogLevelRequest, sso.SetLogLevelResponse]
+	grantAdmin      *connect.Client[sso.GrantAdminRequest, sso.GrantAdminResponse]
+	revokeAdmin     *connect.Client[sso.RevokeAdminRequest, sso.RevokeAdminResponse]
+	grantRole       *connect.Client[sso.GrantRoleRequest, sso.GrantRoleResponse]
+	revokeRole      *connect.Client[sso.RevokeRoleRequest, sso.RevokeRoleResponse]
+	putPolicy       *connect.Client[sso.PutPolicyRequest, sso.PutPolicyResponse]
+	listPolicies    *connect.Client[sso.ListPoliciesRequest, sso.ListPoliciesResponse]
+	createApp       *connect.Client[sso.CreateAppRequest, sso.CreateAppResponse]
+	updateApp       *connect.Client[sso.UpdateAppRequest, sso.UpdateAppResponse]
+	listApps        *connect.Client[sso.ListAppsRequest, sso.ListAppsResponse]
+	deleteApp       *connect.Client[sso.DeleteAppRequest, sso.DeleteAppResponse]
+	rotateAppSecret *connect.Client[sso.RotateAppSecretRequest, sso.RotateAppSecretResponse]
+	addAppMember    *connect.Client[sso.AddAppMemberRequest, sso.AddAppMemberResponse]
+	removeAppMember *connect.Client[sso.RemoveAppMemberRequest, sso.RemoveAppMemberResponse]
+	listAppMembers  *connect.Client[sso.ListAppMembersRequest, sso.ListAppMembersResponse]
+}
+
+// ListAuditEvents calls github.chaykovski.auth.Admin.ListAuditEvents.
+func (c *adminClient) ListAuditEvents(ctx context.Context, req *connect.Request[sso.ListAuditEventsRequest]) (*connect.Response[sso.ListAuditEventsResponse], error) {
+	return c.listAuditEvents.CallUnary(ctx, req)
+}
+
+// GetLogLevel calls github.chaykovski.auth.Admin.GetLogLevel.
+func (c *adminClient) GetLogLevel(ctx context.Context, req *connect.Request[sso.GetLogLevelRequest]) (*connect.Response[sso.GetLogLevelResponse], error) {
+	return c.getLogLevel.CallUnary(ctx, req)
+}
+
+// SetLogLevel calls github.chaykovski.auth.Admin.SetLogLevel.
+func (c *adminClient) SetLogLevel(ctx context.Context, req *connect.Request[sso.SetLogLevelRequest]) (*connect.Response[sso.SetLogLevelResponse], error) {
+	return c.setLogLevel.CallUnary(ctx, req)
+}
+
+// GrantAdmin calls github.chaykovski.auth.Admin.GrantAdmin.
+func (c *adminClient) GrantAdmin(ctx context.Context, req *connect.Request[sso.GrantAdminRequest]) (*connect.Response[sso.GrantAdminResponse], error) {
+	return c.grantAdmin.CallUnary(ctx, req)
+}
+
+// RevokeAdmin calls github.chaykovski.auth.Admin.RevokeAdmin.
+func (c *adminClient) RevokeAdmin(ctx context.Context, req *connect.Request[sso.RevokeAdminRequest]) (*connect.Response[sso.RevokeAdminResponse], error) {
+	return c.revokeAdmin.CallUnary(ctx, req)
+}
+
+// GrantRole calls github.chaykovski.auth.Admin.GrantRole.
+func (c *adminClient) GrantRole(ctx context.Context, req *connect.Request[sso.GrantRoleRequest]) (*connect.Response[sso.GrantRoleResponse], error) {
+	return c.grantRole.CallUnary(ctx, req)
+}
+
+// RevokeRole calls github.chaykovski.auth.Admin.RevokeRole.
+func (c *adminClient) RevokeRole(ctx context.Context, req *connect.Request[sso.RevokeRoleRequest]) (*connect.Response[sso.RevokeRoleResponse], error) {
+	return c.revokeRole.CallUnary(ctx, req)
+}
+
+// PutPolicy calls github.chaykovski.auth.Admin.PutPolicy.
+func (c *adminClient) PutPolicy(ctx context.Context, req *connect.Request[sso.PutPolicyRequest]) (*connect.Response[sso.PutPolicyResponse], error) {
+	return c.putPolicy.CallUnary(ctx, req)
+}
+
+// ListPolicies calls github.chaykovski.auth.Admin.ListPolicies.
+func (c *adminClient) ListPolicies(ctx context.Context, req *connect.Request[sso.ListPoliciesRequest]) (*connect.Response[sso.ListPoliciesResponse], error) {
+	return c.listPolicies.CallUnary(ctx, req)
+}
+
+// CreateApp calls github.chaykovski.auth.Admin.CreateApp.
+func (c *adminClient) CreateApp(ctx context.Context, req *connect.Request[sso.CreateAppRequest]) (*connect.Response[sso.CreateAppResponse], error) {
+	return c.createApp.CallUnary(ctx, req)
+}
+
+// UpdateApp calls github.chaykovski.auth.Admin.UpdateApp.
+func (c *adminClient) UpdateApp(ctx context.Context, req *connect.Request[sso.UpdateAppRequest]) (*connect.Response[sso.UpdateAppResponse], error) {
+	return c.updateApp.CallUnary(ctx, req)
+}
+
+// ListApps calls github.chaykovski.auth.Admin.ListApps.
+func (c *adminClient) ListApps(ctx context.Context, req *connect.Request[sso.ListAppsRequest]) (*connect.Response[sso.ListAppsResponse], error) {
+	return c.listApps.CallUnary(ctx, req)
+}
+
+// DeleteApp calls github.chaykovski.auth.Admin.DeleteApp.
+func (c *adminClient) DeleteApp(ctx context.Context, req *connect.Request[sso.DeleteAppRequest]) (*connect.Response[sso.DeleteAppResponse], error) {
+	return c.deleteApp.CallUnary(ctx, req)
+}
+
+// RotateAppSecret calls github.chaykovski.auth.Admin.RotateAppSecret.
+func (c *adminClient) RotateAppSecret(ctx context.Context, req *connect.Request[sso.RotateAppSecretRequest]) (*connect.Response[sso.RotateAppSecretResponse], error) {
+	return c.rotateAppSecret.CallUnary(ctx, req)
+}
+
+// AddAppMember calls github.chaykovski.auth.Admin.AddAppMember.
+func (c *adminClient) AddAppMember(ctx context.Context, req *connect.Request[sso.AddAppMemberRequest]) (*connect.Response[sso.AddAppMemberResponse], error) {
+	return c.addAppMember.CallUnary(ctx, req)
+}
+
+// RemoveAppMember calls github.chaykovski.auth.Admin.RemoveAppMember.
+func (c *adminClient) RemoveAppMember(ctx context.Context, req *connect.Request[sso.RemoveAppMemberRequest]) (*connect.Response[sso.RemoveAppMemberResponse], error) {
+	return c.removeAppMember.CallUnary(ctx, req)
+}
+
+// ListAppMembers calls github.chaykovski.auth.Admin.ListAppMembers.
+func (c *adminClient) ListAppMembers(ctx context.Context, req *connect.Request[sso.ListAppMembersRequest]) (*connect.Response[sso.ListAppMembersResponse], error) {
+	return c.listAppMembers.CallUnary(ctx, req)
+}
+
+// AdminHandler is an implementation of the github.chaykovski.auth.Admin service.
+type AdminHandler interface {
+	ListAuditEvents(context.Context, *connect.Request[sso.ListAuditEventsRequest]) (*connect.Response[sso.ListAuditEventsResponse], error)
+	GetLogLevel(context.Context, *connect.Request[sso.GetLogLevelRequest]) (*connect.Response[sso.GetLogLevelResponse], error)
+	SetLogLevel(context.Context, *connect.Request[sso.SetLogLevelRequest]) (*connect.Response[sso.SetLogLevelResponse], error)
+	// GrantAdmin grants admin role to user globally, caller must be admin
+	GrantAdmin(context.Context, *connect.Request[sso.GrantAdminRequest]) (*connect.Response[sso.GrantAdminResponse], error)
+	// RevokeAdmin revokes global admin role of user, the last admin can't be revoked
+	RevokeAdmin(context.Context, *connect.Request[sso.RevokeAdminRequest]) (*connect.Response[sso.RevokeAdminResponse], error)
+	// GrantRole grants role to user globally or in the app, caller must be admin
+	GrantRole(context.Context, *connect.Request[sso.GrantRoleRequest]) (*connect.Response[sso.GrantRoleResponse], error)
+	// RevokeRole revokes role of user granted globally or in the app, caller must be admin
+	RevokeRole(context.Context, *connect.Request[sso.RevokeRoleRequest]) (*connect.Response[sso.RevokeRoleResponse], error)
+	// PutPolicy saves new version of policy, the latest version of policy is effective
+	PutPolicy(context.Context, *connect.Request[sso.PutPolicyRequest]) (*connect.Response[sso.PutPolicyResponse], error)
+	// ListPolicies returns the latest versions of all policies or all versions of policy
+	ListPolicies(context.Context, *connect.Request[sso.ListPoliciesRequest]) (*connect.Response[sso.ListPoliciesResponse], error)
+	// CreateApp registers app with generated client id and secret, the secret is returned only once
+	CreateApp(context.Context, *connect.Request[sso.CreateAppRequest]) (*connect.Response[sso.CreateAppResponse], error)
+	// UpdateApp replaces settings of app, disabled app can't be used to log in but keeps its data
+	UpdateApp(context.Context, *connect.Request[sso.UpdateAppRequest]) (*connect.Response[sso.UpdateAppResponse], error)
+	ListApps(context.Context, *connect.Request[sso.ListAppsRequest]) (*connect.Response[sso.ListAppsResponse], error)
+	// DeleteApp deletes app with its sessions, memberships, role assignments and policies
+	DeleteApp(context.Context, *connect.Request[sso.DeleteAppRequest]) (*connect.Response[sso.DeleteAppResponse], error)
+	// RotateAppSecret replaces client secret of app, the new secret is returned only once
+	RotateAppSecret(context.Context, *connect.Request[sso.RotateAppSecretRequest]) (*connect.Response[sso.RotateAppSecretResponse], error)
+	// AddAppMember lets user log in to app requiring membership
+	AddAppMember(context.Context, *connect.Request[sso.AddAppMemberRequest]) (*connect.Response[sso.AddAppMemberResponse], error)
+	// RemoveAppMember removes user from members of app, sessions of the user in the app can't be refreshed
+	// if the app requires membership
+	RemoveAppMember(context.Context, *connect.Request[sso.RemoveAppMemberRequest]) (*connect.Response[sso.RemoveAppMemberResponse], error)
+	ListAppMembers(context.Context, *connect.Request[sso.ListAppMembersRequest]) (*connect.Response[sso.ListAppMembersResponse], error)
+}
+
+// NewAdminHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminHandler(svc AdminHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminMethods := sso.File_sso_admin_proto.Services().ByName("Admin").Methods()
+	adminListAuditEventsHandler := connect.NewUnaryHandler(
+		AdminListAuditEventsProcedure,
+		svc.ListAuditEvents,
+		connect.WithSchema(adminMethods.ByName("ListAuditEvents")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminGetLogLevelHandler := connect.NewUnaryHandler(
+		AdminGetLogLevelProcedure,
+		svc.GetLogLevel,
+		connect.WithSchema(adminMethods.ByName("GetLogLevel")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminSetLogLevelHandler := connect.NewUnaryHandler(
+		AdminSetLogLevelProcedure,
+		svc.SetLogLevel,
+		connect.WithSchema(adminMethods.ByName("SetLogLevel")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminGrantAdminHandler := connect.NewUnaryHandler(
+		AdminGrantAdminProcedure,
+		svc.GrantAdmin,
+		connect.WithSchema(adminMethods.ByName("GrantAdmin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminRevokeAdminHandler := connect.NewUnaryHandler(
+		AdminRevokeAdminProcedure,
+		svc.RevokeAdmin,
+		connect.WithSchema(adminMethods.ByName("RevokeAdmin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminGrantRoleHandler := connect.NewUnaryHandler(
+		AdminGrantRoleProcedure,
+		svc.GrantRole,
+		connect.WithSchema(adminMethods.ByName("GrantRole")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminRevokeRoleHandler := connect.NewUnaryHandler(
+		AdminRevokeRoleProcedure,
+		svc.RevokeRole,
+		connect.WithSchema(adminMethods.ByName("RevokeRole")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminPutPolicyHandler := connect.NewUnaryHandler(
+		AdminPutPolicyProcedure,
+		svc.PutPolicy,
+		connect.WithSchema(adminMethods.ByName("PutPolicy")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminListPoliciesHandler := connect.NewUnaryHandler(
+		AdminListPoliciesProcedure,
+		svc.ListPolicies,
+		connect.WithSchema(adminMethods.ByName("ListPolicies")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminCreateAppHandler := connect.NewUnaryHandler(
+		AdminCreateAppProcedure,
+		svc.CreateApp,
+		connect.WithSchema(adminMethods.ByName("CreateApp")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminUpdateAppHandler := connect.NewUnaryHandler(
+		AdminUpdateAppProcedure,
+		svc.UpdateApp,
+		connect.WithSchema(adminMethods.ByName("UpdateApp")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminListAppsHandler := connect.NewUnaryHandler(
+		AdminListAppsProcedure,
+		svc.ListApps,
+		connect.WithSchema(adminMethods.ByName("ListApps")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminDeleteAppHandler := connect.NewUnaryHandler(
+		AdminDeleteAppProcedure,
+		svc.DeleteApp,
+		connect.WithSchema(adminMethods.ByName("DeleteApp")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminRotateAppSecretHandler := connect.NewUnaryHandler(
+		AdminRotateAppSecretProcedure,
+		svc.RotateAppSecret,
+		connect.WithSchema(adminMethods.ByName("RotateAppSecret")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminAddAppMemberHandler := connect.NewUnaryHandler(
+		AdminAddAppMemberProcedure,
+		svc.AddAppMember,
+		connect.WithSchema(adminMethods.ByName("AddAppMember")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminRemoveAppMemberHandler := connect.NewUnaryHandler(
+		AdminRemoveAppMemberProcedure,
+		svc.RemoveAppMember,
+		connect.WithSchema(adminMethods.ByName("RemoveAppMember")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminListAppMembersHandler := connect.NewUnaryHandler(
+		AdminListAppMembersProcedure,
+		svc.ListAppMembers,
+		connect.WithSchema(adminMethods.ByName("ListAppMembers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/github.chaykovski.auth.Admin/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminListAuditEventsProcedure:
+			adminListAuditEventsHandler.ServeHTTP(w, r)
+		case AdminGetLogLevelProcedure:
+			adminGetLogLevelHandler.ServeHTTP(w, r)
+		case AdminSetLogLevelProcedure:
+			adminSetLogLevelHandler.ServeHTTP(w, r)
+		case AdminGrantAdminProcedure:
+			adminGrantAdminHandler.ServeHTTP(w, r)
+		case AdminRevokeAdminProcedure:
+			adminRevokeAdminHandler.ServeHTTP(w, r)
+		case AdminGrantRoleProcedure:
+			adminGrantRoleHandler.ServeHTTP(w, r)
+		case AdminRevokeRoleProcedure:
+			adminRevokeRoleHandler.ServeHTTP(w, r)
+		case AdminPutPolicyProcedure:
+			adminPutPolicyHandler.ServeHTTP(w, r)
+		case AdminListPoliciesProcedure:
+			adminListPoliciesHandler.ServeHTTP(w, r)
+		case AdminCreateAppProcedure:
+			adminCreateAppHandler.ServeHTTP(w, r)
+		case AdminUpdateAppProcedure:
+			adminUpdateAppHandler.ServeHTTP(w, r)
+		case AdminListAppsProcedure:
+			adminListAppsHandler.ServeHTTP(w, r)
+		case AdminDeleteAppProcedure:
+			adminDeleteAppHandler.ServeHTTP(w, r)
+		case AdminRotateAppSecretProcedure:
+			adminRotateAppSecretHandler.ServeHTTP(w, r)
+		case AdminAddAppMemberProcedure:
+			adminAddAppMemberHandler.ServeHTTP(w, r)
+		case AdminRemoveAppMemberProcedure:
+			adminRemoveAppMemberHandler.ServeHTTP(w, r)
+		case AdminListAppMembersProcedure:
+			adminListAppMembersHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminHandler struct{}
+
+func (UnimplementedAdminHandler) ListAuditEvents(context.Context, *connect.Request[sso.ListAuditEventsRequest]) (*connect.Response[sso.ListAuditEventsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.ListAuditEvents is not implemented"))
+}
+
+func (UnimplementedAdminHandler) GetLogLevel(context.Context, *connect.Request[sso.GetLogLevelRequest]) (*connect.Response[sso.GetLogLevelResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.GetLogLevel is not implemented"))
+}
+
+func (UnimplementedAdminHandler) SetLogLevel(context.Context, *connect.Request[sso.SetLogLevelRequest]) (*connect.Response[sso.SetLogLevelResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.SetLogLevel is not implemented"))
+}
+
+func (UnimplementedAdminHandler) GrantAdmin(context.Context, *connect.Request[sso.GrantAdminRequest]) (*connect.Response[sso.GrantAdminResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.GrantAdmin is not implemented"))
+}
+
+func (UnimplementedAdminHandler) RevokeAdmin(context.Context, *connect.Request[sso.RevokeAdminRequest]) (*connect.Response[sso.RevokeAdminResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.RevokeAdmin is not implemented"))
+}
+
+func (UnimplementedAdminHandler) GrantRole(context.Context, *connect.Request[sso.GrantRoleRequest]) (*connect.Response[sso.GrantRoleResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.GrantRole is not implemented"))
+}
+
+func (UnimplementedAdminHandler) RevokeRole(context.Context, *connect.Request[sso.RevokeRoleRequest]) (*connect.Response[sso.RevokeRoleResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.RevokeRole is not implemented"))
+}
+
+func (UnimplementedAdminHandler) PutPolicy(context.Context, *connect.Request[sso.PutPolicyRequest]) (*connect.Response[sso.PutPolicyResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.PutPolicy is not implemented"))
+}
+
+func (UnimplementedAdminHandler) ListPolicies(context.Context, *connect.Request[sso.ListPoliciesRequest]) (*connect.Response[sso.ListPoliciesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.ListPolicies is not implemented"))
+}
+
+func (UnimplementedAdminHandler) CreateApp(context.Context, *connect.Request[sso.CreateAppRequest]) (*connect.Response[sso.CreateAppResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.CreateApp is not implemented"))
+}
+
+func (UnimplementedAdminHandler) UpdateApp(context.Context, *connect.Request[sso.UpdateAppRequest]) (*connect.Response[sso.UpdateAppResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.UpdateApp is not implemented"))
+}
+
+func (UnimplementedAdminHandler) ListApps(context.Context, *connect.Request[sso.ListAppsRequest]) (*connect.Response[sso.ListAppsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.ListApps is not implemented"))
+}
+
+func (UnimplementedAdminHandler) DeleteApp(context.Context, *connect.Request[sso.DeleteAppRequest]) (*connect.Response[sso.DeleteAppResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.DeleteApp is not implemented"))
+}
+
+func (UnimplementedAdminHandler) RotateAppSecret(context.Context, *connect.Request[sso.RotateAppSecretRequest]) (*connect.Response[sso.RotateAppSecretResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.RotateAppSecret is not implemented"))
+}
+
+func (UnimplementedAdminHandler) AddAppMember(context.Context, *connect.Request[sso.AddAppMemberRequest]) (*connect.Response[sso.AddAppMemberResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.AddAppMember is not implemented"))
+}
+
+func (UnimplementedAdminHandler) RemoveAppMember(context.Context, *connect.Request[sso.RemoveAppMemberRequest]) (*connect.Response[sso.RemoveAppMemberResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.RemoveAppMember is not implemented"))
+}
+
+func (UnimplementedAdminHandler) ListAppMembers(context.Context, *connect.Request[sso.ListAppMembersRequest]) (*connect.Response[sso.ListAppMembersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Admin.ListAppMembers is not implemented"))
+}
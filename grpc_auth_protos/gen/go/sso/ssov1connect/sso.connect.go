@@ -0,0 +1,334 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: sso/sso.proto
+
+package ssov1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	sso "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// AuthName is the fully-qualified name of the Auth service.
+	AuthName = "github.chaykovski.auth.Auth"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AuthRegisterProcedure is the fully-qualified name of the Auth's Register RPC.
+	AuthRegisterProcedure = "/github.chaykovski.auth.Auth/Register"
+	// AuthLoginProcedure is the fully-qualified name of the Auth's Login RPC.
+	AuthLoginProcedure = "/github.chaykovski.auth.Auth/Login"
+	// AuthIsAdminProcedure is the fully-qualified name of the Auth's IsAdmin RPC.
+	AuthIsAdminProcedure = "/github.chaykovski.auth.Auth/IsAdmin"
+	// AuthListSessionsProcedure is the fully-qualified name of the Auth's ListSessions RPC.
+	AuthListSessionsProcedure = "/github.chaykovski.auth.Auth/ListSessions"
+	// AuthRevokeSessionProcedure is the fully-qualified name of the Auth's RevokeSession RPC.
+	AuthRevokeSessionProcedure = "/github.chaykovski.auth.Auth/RevokeSession"
+	// AuthRevokeAllSessionsProcedure is the fully-qualified name of the Auth's RevokeAllSessions RPC.
+	AuthRevokeAllSessionsProcedure = "/github.chaykovski.auth.Auth/RevokeAllSessions"
+	// AuthCheckPermissionProcedure is the fully-qualified name of the Auth's CheckPermission RPC.
+	AuthCheckPermissionProcedure = "/github.chaykovski.auth.Auth/CheckPermission"
+	// AuthListUserRolesProcedure is the fully-qualified name of the Auth's ListUserRoles RPC.
+	AuthListUserRolesProcedure = "/github.chaykovski.auth.Auth/ListUserRoles"
+	// AuthAuthorizeProcedure is the fully-qualified name of the Auth's Authorize RPC.
+	AuthAuthorizeProcedure = "/github.chaykovski.auth.Auth/Authorize"
+)
+
+// AuthClient is a client for the github.chaykovski.auth.Auth service.
+type AuthClient interface {
+	Register(context.Context, *connect.Request[sso.RegisterRequest]) (*connect.Response[sso.RegisterResponse], error)
+	Login(context.Context, *connect.Request[sso.LoginRequest]) (*connect.Response[sso.LoginResponse], error)
+	IsAdmin(context.Context, *connect.Request[sso.IsAdminRequest]) (*connect.Response[sso.IsAdminResponse], error)
+	ListSessions(context.Context, *connect.Request[sso.ListSessionsRequest]) (*connect.Response[sso.ListSessionsResponse], error)
+	RevokeSession(context.Context, *connect.Request[sso.RevokeSessionRequest]) (*connect.Response[sso.RevokeSessionResponse], error)
+	RevokeAllSessions(context.Context, *connect.Request[sso.RevokeAllSessionsRequest]) (*connect.Response[sso.RevokeAllSessionsResponse], error)
+	CheckPermission(context.Context, *connect.Request[sso.CheckPermissionRequest]) (*connect.Response[sso.CheckPermissionResponse], error)
+	ListUserRoles(context.Context, *connect.Request[sso.ListUserRolesRequest]) (*connect.Response[sso.ListUserRolesResponse], error)
+	// Authorize evaluates policies of action against attributes of user, app, roles and resource
+	Authorize(context.Context, *connect.Request[sso.AuthorizeRequest]) (*connect.Response[sso.AuthorizeResponse], error)
+}
+
+// NewAuthClient constructs a client for the github.chaykovski.auth.Auth service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAuthClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AuthClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	authMethods := sso.File_sso_sso_proto.Services().ByName("Auth").Methods()
+	return &authClient{
+		register: connect.NewClient[sso.RegisterRequest, sso.RegisterResponse](
+			httpClient,
+			baseURL+AuthRegisterProcedure,
+			connect.WithSchema(authMethods.ByName("Register")),
+			connect.WithClientOptions(opts...),
+		),
+		login: connect.NewClient[sso.LoginRequest, sso.LoginResponse](
+			httpClient,
+			baseURL+AuthLoginProcedure,
+			connect.WithSchema(authMethods.ByName("Login")),
+			connect.WithClientOptions(opts...),
+		),
+		isAdmin: connect.NewClient[sso.IsAdminRequest, sso.IsAdminResponse](
+			httpClient,
+			baseURL+AuthIsAdminProcedure,
+			connect.WithSchema(authMethods.ByName("IsAdmin")),
+			connect.WithClientOptions(opts...),
+		),
+		listSessions: connect.NewClient[sso.ListSessionsRequest, sso.ListSessionsResponse](
+			httpClient,
+			baseURL+AuthListSessionsProcedure,
+			connect.WithSchema(authMethods.ByName("ListSessions")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeSession: connect.NewClient[sso.RevokeSessionRequest, sso.RevokeSessionResponse](
+			httpClient,
+			baseURL+AuthRevokeSessionProcedure,
+			connect.WithSchema(authMethods.ByName("RevokeSession")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeAllSessions: connect.NewClient[sso.RevokeAllSessionsRequest, sso.RevokeAllSessionsResponse](
+			httpClient,
+			baseURL+AuthRevokeAllSessionsProcedure,
+			connect.WithSchema(authMethods.ByName("RevokeAllSessions")),
+			connect.WithClientOptions(opts...),
+		),
+		checkPermission: connect.NewClient[sso.CheckPermissionRequest, sso.CheckPermissionResponse](
+			httpClient,
+			baseURL+AuthCheckPermissionProcedure,
+			connect.WithSchema(authMethods.ByName("CheckPermission")),
+			connect.WithClientOptions(opts...),
+		),
+		listUserRoles: connect.NewClient[sso.ListUserRolesRequest, sso.ListUserRolesResponse](
+			httpClient,
+			baseURL+AuthListUserRolesProcedure,
+			connect.WithSchema(authMethods.ByName("ListUserRoles")),
+			connect.WithClientOptions(opts...),
+		),
+		authorize: connect.NewClient[sso.AuthorizeRequest, sso.AuthorizeResponse](
+			httpClient,
+			baseURL+AuthAuthorizeProcedure,
+			connect.WithSchema(authMethods.ByName("Authorize")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// authClient implements AuthClient.
+type authClient struct {
+	register          *connect.Client[sso.RegisterRequest, sso.RegisterResponse]
+	login             *connect.Client[sso.LoginRequest, sso.LoginResponse]
+	isAdmin           *connect.Client[sso.IsAdminRequest, sso.IsAdminResponse]
+	listSessions      *connect.Client[sso.ListSessionsRequest, sso.ListSessionsResponse]
+	revokeSession     *connect.Client[sso.RevokeSessionRequest, sso.RevokeSessionResponse]
+	revokeAllSessions *connect.Client[sso.RevokeAllSessionsRequest, sso.RevokeAllSessionsResponse]
+	checkPermission   *connect.Client[sso.CheckPermissionRequest, sso.CheckPermissionResponse]
+	listUserRoles     *connect.Client[sso.ListUserRolesRequest, sso.ListUserRolesResponse]
+	authorize         *connect.Client[sso.AuthorizeRequest, sso.AuthorizeResponse]
+}
+
+// Register calls github.chaykovski.auth.Auth.Register.
+func (c *authClient) Register(ctx context.Context, req *connect.Request[sso.RegisterRequest]) (*connect.Response[sso.RegisterResponse], error) {
+	return c.register.CallUnary(ctx, req)
+}
+
+// Login calls github.chaykovski.auth.Auth.Login.
+func (c *authClient) Login(ctx context.Context, req *connect.Request[sso.LoginRequest]) (*connect.Response[sso.LoginResponse], error) {
+	return c.login.CallUnary(ctx, req)
+}
+
+// IsAdmin calls github.chaykovski.auth.Auth.IsAdmin.
+func (c *authClient) IsAdmin(ctx context.Context, req *connect.Request[sso.IsAdminRequest]) (*connect.Response[sso.IsAdminResponse], error) {
+	return c.isAdmin.CallUnary(ctx, req)
+}
+
+// ListSessions calls github.chaykovski.auth.Auth.ListSessions.
+func (c *authClient) ListSessions(ctx context.Context, req *connect.Request[sso.ListSessionsRequest]) (*connect.Response[sso.ListSessionsResponse], error) {
+	return c.listSessions.CallUnary(ctx, req)
+}
+
+// RevokeSession calls github.chaykovski.auth.Auth.RevokeSession.
+func (c *authClient) RevokeSession(ctx context.Context, req *connect.Request[sso.RevokeSessionRequest]) (*connect.Response[sso.RevokeSessionResponse], error) {
+	return c.revokeSession.CallUnary(ctx, req)
+}
+
+// RevokeAllSessions calls github.chaykovski.auth.Auth.RevokeAllSessions.
+func (c *authClient) RevokeAllSessions(ctx context.Context, req *connect.Request[sso.RevokeAllSessionsRequest]) (*connect.Response[sso.RevokeAllSessionsResponse], error) {
+	return c.revokeAllSessions.CallUnary(ctx, req)
+}
+
+// CheckPermission calls github.chaykovski.auth.Auth.CheckPermission.
+func (c *authClient) CheckPermission(ctx context.Context, req *connect.Request[sso.CheckPermissionRequest]) (*connect.Response[sso.CheckPermissionResponse], error) {
+	return c.checkPermission.CallUnary(ctx, req)
+}
+
+// ListUserRoles calls github.chaykovski.auth.Auth.ListUserRoles.
+func (c *authClient) ListUserRoles(ctx context.Context, req *connect.Request[sso.ListUserRolesRequest]) (*connect.Response[sso.ListUserRolesResponse], error) {
+	return c.listUserRoles.CallUnary(ctx, req)
+}
+
+// Authorize calls github.chaykovski.auth.Auth.Authorize.
+func (c *authClient) Authorize(ctx context.Context, req *connect.Request[sso.AuthorizeRequest]) (*connect.Response[sso.AuthorizeResponse], error) {
+	return c.authorize.CallUnary(ctx, req)
+}
+
+// AuthHandler is an implementation of the github.chaykovski.auth.Auth service.
+type AuthHandler interface {
+	Register(context.Context, *connect.Request[sso.RegisterRequest]) (*connect.Response[sso.RegisterResponse], error)
+	Login(context.Context, *connect.Request[sso.LoginRequest]) (*connect.Response[sso.LoginResponse], error)
+	IsAdmin(context.Context, *connect.Request[sso.IsAdminRequest]) (*connect.Response[sso.IsAdminResponse], error)
+	ListSessions(context.Context, *connect.Request[sso.ListSessionsRequest]) (*connect.Response[sso.ListSessionsResponse], error)
+	RevokeSession(context.Context, *connect.Request[sso.RevokeSessionRequest]) (*connect.Response[sso.RevokeSessionResponse], error)
+	RevokeAllSessions(context.Context, *connect.Request[sso.RevokeAllSessionsRequest]) (*connect.Response[sso.RevokeAllSessionsResponse], error)
+	CheckPermission(context.Context, *connect.Request[sso.CheckPermissionRequest]) (*connect.Response[sso.CheckPermissionResponse], error)
+	ListUserRoles(context.Context, *connect.Request[sso.ListUserRolesRequest]) (*connect.Response[sso.ListUserRolesResponse], error)
+	// Authorize evaluates policies of action against attributes of user, app, roles and resource
+	Authorize(context.Context, *connect.Request[sso.AuthorizeRequest]) (*connect.Response[sso.AuthorizeResponse], error)
+}
+
+// NewAuthHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAuthHandler(svc AuthHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	authMethods := sso.File_sso_sso_proto.Services().ByName("Auth").Methods()
+	authRegisterHandler := connect.NewUnaryHandler(
+		AuthRegisterProcedure,
+		svc.Register,
+		connect.WithSchema(authMethods.ByName("Register")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authLoginHandler := connect.NewUnaryHandler(
+		AuthLoginProcedure,
+		svc.Login,
+		connect.WithSchema(authMethods.ByName("Login")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authIsAdminHandler := connect.NewUnaryHandler(
+		AuthIsAdminProcedure,
+		svc.IsAdmin,
+		connect.WithSchema(authMethods.ByName("IsAdmin")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authListSessionsHandler := connect.NewUnaryHandler(
+		AuthListSessionsProcedure,
+		svc.ListSessions,
+		connect.WithSchema(authMethods.ByName("ListSessions")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authRevokeSessionHandler := connect.NewUnaryHandler(
+		AuthRevokeSessionProcedure,
+		svc.RevokeSession,
+		connect.WithSchema(authMethods.ByName("RevokeSession")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authRevokeAllSessionsHandler := connect.NewUnaryHandler(
+		AuthRevokeAllSessionsProcedure,
+		svc.RevokeAllSessions,
+		connect.WithSchema(authMethods.ByName("RevokeAllSessions")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authCheckPermissionHandler := connect.NewUnaryHandler(
+		AuthCheckPermissionProcedure,
+		svc.CheckPermission,
+		connect.WithSchema(authMethods.ByName("CheckPermission")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authListUserRolesHandler := connect.NewUnaryHandler(
+		AuthListUserRolesProcedure,
+		svc.ListUserRoles,
+		connect.WithSchema(authMethods.ByName("ListUserRoles")),
+		connect.WithHandlerOptions(opts...),
+	)
+	authAuthorizeHandler := connect.NewUnaryHandler(
+		AuthAuthorizeProcedure,
+		svc.Authorize,
+		connect.WithSchema(authMethods.ByName("Authorize")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/github.chaykovski.auth.Auth/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AuthRegisterProcedure:
+			authRegisterHandler.ServeHTTP(w, r)
+		case AuthLoginProcedure:
+			authLoginHandler.ServeHTTP(w, r)
+		case AuthIsAdminProcedure:
+			authIsAdminHandler.ServeHTTP(w, r)
+		case AuthListSessionsProcedure:
+			authListSessionsHandler.ServeHTTP(w, r)
+		case AuthRevokeSessionProcedure:
+			authRevokeSessionHandler.ServeHTTP(w, r)
+		case AuthRevokeAllSessionsProcedure:
+			authRevokeAllSessionsHandler.ServeHTTP(w, r)
+		case AuthCheckPermissionProcedure:
+			authCheckPermissionHandler.ServeHTTP(w, r)
+		case AuthListUserRolesProcedure:
+			authListUserRolesHandler.ServeHTTP(w, r)
+		case AuthAuthorizeProcedure:
+			authAuthorizeHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAuthHandler returns CodeUnimplemented from all methods.
+type UnimplementedAuthHandler struct{}
+
+func (UnimplementedAuthHandler) Register(context.Context, *connect.Request[sso.RegisterRequest]) (*connect.Response[sso.RegisterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.Register is not implemented"))
+}
+
+func (UnimplementedAuthHandler) Login(context.Context, *connect.Request[sso.LoginRequest]) (*connect.Response[sso.LoginResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.Login is not implemented"))
+}
+
+func (UnimplementedAuthHandler) IsAdmin(context.Context, *connect.Request[sso.IsAdminRequest]) (*connect.Response[sso.IsAdminResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.IsAdmin is not implemented"))
+}
+
+func (UnimplementedAuthHandler) ListSessions(context.Context, *connect.Request[sso.ListSessionsRequest]) (*connect.Response[sso.ListSessionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.ListSessions is not implemented"))
+}
+
+func (UnimplementedAuthHandler) RevokeSession(context.Context, *connect.Request[sso.RevokeSessionRequest]) (*connect.Response[sso.RevokeSessionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.RevokeSession is not implemented"))
+}
+
+func (UnimplementedAuthHandler) RevokeAllSessions(context.Context, *connect.Request[sso.RevokeAllSessionsRequest]) (*connect.Response[sso.RevokeAllSessionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.RevokeAllSessions is not implemented"))
+}
+
+func (UnimplementedAuthHandler) CheckPermission(context.Context, *connect.Request[sso.CheckPermissionRequest]) (*connect.Response[sso.CheckPermissionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.CheckPermission is not implemented"))
+}
+
+func (UnimplementedAuthHandler) ListUserRoles(context.Context, *connect.Request[sso.ListUserRolesRequest]) (*connect.Response[sso.ListUserRolesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.ListUserRoles is not implemented"))
+}
+
+func (UnimplementedAuthHandler) Authorize(context.Context, *connect.Request[sso.AuthorizeRequest]) (*connect.Response[sso.AuthorizeResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("github.chaykovski.auth.Auth.Authorize is not implemented"))
+}
@@ -0,0 +1,9 @@
+// Package openapiv2 embeds OpenAPI document generated from sso protos
+package openapiv2
+
+import _ "embed"
+
+// Spec is OpenAPI v2 document of sso HTTP/JSON API
+//
+//go:embed sso.swagger.json
+var Spec []byte
@@ -0,0 +1,3 @@
+module github.com/4aykovski/grpc_auth_protos
+
+go 1.22.3
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+)
+
+// FileSink appends audit events to a file in JSON lines format
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+type fileRecord struct {
+	ID        int64             `json:"id"`
+	Type      string            `json:"type"`
+	Success   bool              `json:"success"`
+	Reason    string            `json:"reason,omitempty"`
+	ActorID   int64             `json:"actor_id,omitempty"`
+	SubjectID int64             `json:"subject_id,omitempty"`
+	AppID     int               `json:"app_id,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewFileSink opens file at path for appending, creating it if necessary
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	return &FileSink{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+// Write appends event to the file as a single JSON line
+func (s *FileSink) Write(_ context.Context, event entity.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.enc.Encode(fileRecord{
+		ID:        event.ID,
+		Type:      event.Type,
+		Success:   event.Success,
+		Reason:    event.Reason,
+		ActorID:   event.ActorID,
+		SubjectID: event.SubjectID,
+		AppID:     event.AppID,
+		IP:        event.IP,
+		RequestID: event.RequestID,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes written events to disk and closes the file
+//
+// Events must not be written after Close
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return fmt.Errorf("failed to sync audit file: %w", err)
+	}
+
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	events := []entity.AuditEvent{
+		{ID: 1, Type: entity.AuditEventLogin, Success: true, ActorID: 7, AppID: 1, IP: "10.0.0.1", CreatedAt: createdAt},
+		{ID: 2, Type: entity.AuditEventLogin, Reason: "invalid_credentials", Details: map[string]string{"email": "user@example.com"}, CreatedAt: createdAt},
+	}
+	for _, event := range events {
+		require.NoError(t, sink.Write(context.Background(), event))
+	}
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []fileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record fileRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, records, 2)
+	assert.Equal(t, fileRecord{ID: 1, Type: "login", Success: true, ActorID: 7, AppID: 1, IP: "10.0.0.1", CreatedAt: createdAt}, records[0])
+	assert.Equal(t, fileRecord{ID: 2, Type: "login", Reason: "invalid_credentials", Details: map[string]string{"email": "user@example.com"}, CreatedAt: createdAt}, records[1])
+}
+
+func TestFileSink_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for id := int64(1); id <= 2; id++ {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), entity.AuditEvent{ID: id, Type: entity.AuditEventLogin}))
+		require.NoError(t, sink.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
+
+func TestFileSink_WriteAfterClose(t *testing.T) {
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	assert.Error(t, sink.Write(context.Background(), entity.AuditEvent{Type: entity.AuditEventLogin}))
+}
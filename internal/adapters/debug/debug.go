@@ -0,0 +1,35 @@
+package debug
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+)
+
+// NewHandler returns HTTP handler serving pprof profiles under /debug/pprof/
+//
+// Profiles expose internals of the process and have no authentication,
+// so they are served only to clients connecting from loopback addresses
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return loopbackOnly(mux)
+}
+
+// loopbackOnly forbids requests of clients connecting from non loopback addresses
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
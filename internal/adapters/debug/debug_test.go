@@ -0,0 +1,34 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandler_LoopbackOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		code       int
+	}{
+		{name: "IPv4 loopback", remoteAddr: "127.0.0.1:50000", code: http.StatusOK},
+		{name: "IPv6 loopback", remoteAddr: "[::1]:50000", code: http.StatusOK},
+		{name: "remote client", remoteAddr: "192.0.2.1:50000", code: http.StatusForbidden},
+		{name: "unix socket", remoteAddr: "@", code: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			NewHandler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_protos/gen/openapiv2"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+)
+
+const (
+	headerRequestID          = "x-request-id"
+	headerUserAgent          = "user-agent"
+	headerForwardedUserAgent = "x-forwarded-user-agent"
+	headerAcceptLanguage     = "accept-language"
+)
+
+// New returns HTTP handler which transcodes JSON requests to gRPC calls over conn
+//
+// Routes are taken from google.api.http annotations, methods without annotation are served
+// on POST /{package.Service}/{Method}. gRPC status codes are mapped to HTTP statuses by
+// runtime.HTTPStatusFromCode and errors are returned as JSON google.rpc.Status.
+// OpenAPI document of the API is served on GET /openapi.json
+func New(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	gwMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+	)
+
+	if err := ssov1.RegisterAuthHandler(ctx, gwMux, conn); err != nil {
+		return nil, fmt.Errorf("failed to register auth gateway: %w", err)
+	}
+	if err := ssov1.RegisterAdminHandler(ctx, gwMux, conn); err != nil {
+		return nil, fmt.Errorf("failed to register admin gateway: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gwMux)
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openapiv2.Spec)
+	})
+
+	return mux, nil
+}
+
+// incomingHeader passes request id and accept-language to gRPC metadata as is and user agent as x-forwarded-user-agent,
+// other headers are forwarded by default rules
+func incomingHeader(key string) (string, bool) {
+	switch {
+	case strings.EqualFold(key, headerRequestID):
+		return headerRequestID, true
+	case strings.EqualFold(key, headerAcceptLanguage):
+		return headerAcceptLanguage, true
+	case strings.EqualFold(key, headerUserAgent):
+		return headerForwardedUserAgent, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeader returns request id in x-request-id header like gRPC server does,
+// other response metadata is returned with Grpc-Metadata- prefix
+func outgoingHeader(key string) (string, bool) {
+	if strings.EqualFold(key, headerRequestID) {
+		return textproto.CanonicalMIMEHeaderKey(headerRequestID), true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
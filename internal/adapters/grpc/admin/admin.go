@@ -0,0 +1,488 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	appservice "github.com/4aykovski/grpc_auth_sso/internal/service/app"
+	auditservice "github.com/4aykovski/grpc_auth_sso/internal/service/audit"
+	authservice "github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	policyservice "github.com/4aykovski/grpc_auth_sso/internal/service/policy"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/principal"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AuditService interface {
+	ListEvents(ctx context.Context, dto auditservice.ListEventsDTO) ([]entity.AuditEvent, string, error)
+}
+
+// AccessService manages role assignments on behalf of authenticated admins
+type AccessService interface {
+	GrantRole(ctx context.Context, dto authservice.GrantRoleDTO) (bool, error)
+	RevokeRole(ctx context.Context, dto authservice.RevokeRoleDTO) (bool, error)
+}
+
+// PolicyService manages authorization policies
+type PolicyService interface {
+	PutPolicy(ctx context.Context, dto policyservice.PutPolicyDTO) (entity.Policy, error)
+	ListPolicies(ctx context.Context, name string) ([]entity.Policy, error)
+}
+
+// AppService manages registered apps
+type AppService interface {
+	CreateApp(ctx context.Context, dto appservice.CreateAppDTO) (entity.App, string, error)
+	UpdateApp(ctx context.Context, dto appservice.UpdateAppDTO) (entity.App, error)
+	ListApps(ctx context.Context) ([]entity.App, error)
+	DeleteApp(ctx context.Context, dto appservice.DeleteAppDTO) error
+	RotateAppSecret(ctx context.Context, dto appservice.RotateAppSecretDTO) (string, error)
+	AddMember(ctx context.Context, dto appservice.AppMemberDTO) (bool, error)
+	RemoveMember(ctx context.Context, dto appservice.AppMemberDTO) (bool, error)
+	ListMembers(ctx context.Context, appId int) ([]entity.AppMember, error)
+}
+
+// LogLevel is a level of the running logger, e.g. *slog.LevelVar
+type LogLevel interface {
+	Level() slog.Level
+	Set(level slog.Level)
+}
+
+type serverAPI struct {
+	ssov1.UnimplementedAdminServer
+
+	log *slog.Logger
+
+	validate      *validator.Validate
+	auditService  AuditService
+	accessService AccessService
+	policyService PolicyService
+	appService    AppService
+	logLevel      LogLevel
+}
+
+func Register(
+	gRPC *grpc.Server,
+	log *slog.Logger,
+	auditService AuditService,
+	accessService AccessService,
+	policyService PolicyService,
+	appService AppService,
+	logLevel LogLevel,
+) {
+	ssov1.RegisterAdminServer(gRPC, &serverAPI{
+		log:           log,
+		validate:      validator.New(),
+		auditService:  auditService,
+		accessService: accessService,
+		policyService: policyService,
+		appService:    appService,
+		logLevel:      logLevel,
+	})
+}
+
+func (s *serverAPI) ListAuditEvents(
+	ctx context.Context,
+	req *ssov1.ListAuditEventsRequest,
+) (*ssov1.ListAuditEventsResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateListAuditEventsRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid listAuditEvents request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	dto := auditservice.ListEventsDTO{
+		Type:      req.GetType(),
+		ActorId:   req.GetActorId(),
+		SubjectId: req.GetSubjectId(),
+		AppId:     int(req.GetAppId()),
+		Success:   req.Success,
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	}
+	if req.GetFrom() != nil {
+		dto.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		dto.To = req.GetTo().AsTime()
+	}
+
+	events, nextPageToken, err := s.auditService.ListEvents(ctx, dto)
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list audit events", err)
+	}
+
+	resp := &ssov1.ListAuditEventsResponse{
+		Events:        make([]*ssov1.AuditEvent, 0, len(events)),
+		NextPageToken: nextPageToken,
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, toProtoAuditEvent(event))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) GetLogLevel(
+	_ context.Context,
+	_ *ssov1.GetLogLevelRequest,
+) (*ssov1.GetLogLevelResponse, error) {
+	return &ssov1.GetLogLevelResponse{
+		Level: s.logLevel.Level().String(),
+	}, nil
+}
+
+func (s *serverAPI) SetLogLevel(
+	ctx context.Context,
+	req *ssov1.SetLogLevelRequest,
+) (*ssov1.SetLogLevelResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.GetLevel())); err != nil {
+		log.Info("invalid setLogLevel request", slog.String("error", "invalid level"))
+
+		return nil, apierror.BadRequest(ctx, []apierror.FieldViolation{
+			apierror.Violation("level", "invalid_level"),
+		})
+	}
+
+	previous := s.logLevel.Level()
+	s.logLevel.Set(level)
+
+	log.Warn("log level changed", slog.String("previous", previous.String()), slog.String("level", level.String()))
+
+	return &ssov1.SetLogLevelResponse{
+		PreviousLevel: previous.String(),
+		Level:         level.String(),
+	}, nil
+}
+
+func (s *serverAPI) GrantAdmin(
+	ctx context.Context,
+	req *ssov1.GrantAdminRequest,
+) (*ssov1.GrantAdminResponse, error) {
+
+	granted, err := s.grantRole(ctx, req.GetUserId(), entity.RoleAdmin, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssov1.GrantAdminResponse{
+		Granted: granted,
+	}, nil
+}
+
+func (s *serverAPI) RevokeAdmin(
+	ctx context.Context,
+	req *ssov1.RevokeAdminRequest,
+) (*ssov1.RevokeAdminResponse, error) {
+
+	revoked, err := s.revokeRole(ctx, req.GetUserId(), entity.RoleAdmin, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssov1.RevokeAdminResponse{
+		Revoked: revoked,
+	}, nil
+}
+
+func (s *serverAPI) GrantRole(
+	ctx context.Context,
+	req *ssov1.GrantRoleRequest,
+) (*ssov1.GrantRoleResponse, error) {
+
+	granted, err := s.grantRole(ctx, req.GetUserId(), req.GetRole(), int(req.GetAppId()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssov1.GrantRoleResponse{
+		Granted: granted,
+	}, nil
+}
+
+func (s *serverAPI) RevokeRole(
+	ctx context.Context,
+	req *ssov1.RevokeRoleRequest,
+) (*ssov1.RevokeRoleResponse, error) {
+
+	revoked, err := s.revokeRole(ctx, req.GetUserId(), req.GetRole(), int(req.GetAppId()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssov1.RevokeRoleResponse{
+		Revoked: revoked,
+	}, nil
+}
+
+func (s *serverAPI) PutPolicy(
+	ctx context.Context,
+	req *ssov1.PutPolicyRequest,
+) (*ssov1.PutPolicyResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validatePutPolicyRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid putPolicy request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	policy, err := s.policyService.PutPolicy(ctx, policyservice.PutPolicyDTO{
+		ActorId:     actor.UserID,
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Action:      req.GetAction(),
+		AppId:       int(req.GetAppId()),
+		Effect:      req.GetEffect(),
+		Expression:  req.GetExpression(),
+		DryRun:      req.GetDryRun(),
+		Enabled:     req.GetEnabled(),
+	})
+	if err != nil {
+		var exprErr *policyservice.ExpressionError
+		if errors.As(err, &exprErr) {
+			log.Info("invalid policy expression", slog.String("policy", req.GetName()), slog.String("error", exprErr.Error()))
+
+			return nil, apierror.New(ctx, codes.InvalidArgument, apierror.ReasonInvalidPolicy, "invalid_policy", &errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+					Field:       "expression",
+					Description: exprErr.Err.Error(),
+				}},
+			})
+		}
+
+		return nil, apierror.LogServiceError(ctx, log, "failed to put policy", err, slog.String("policy", req.GetName()))
+	}
+
+	log.Warn("policy saved",
+		slog.Int64("actorId", actor.UserID),
+		slog.String("policy", policy.Name),
+		slog.Int("version", policy.Version),
+	)
+
+	return &ssov1.PutPolicyResponse{
+		Policy: toProtoPolicy(policy),
+	}, nil
+}
+
+func (s *serverAPI) ListPolicies(
+	ctx context.Context,
+	req *ssov1.ListPoliciesRequest,
+) (*ssov1.ListPoliciesResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	policies, err := s.policyService.ListPolicies(ctx, req.GetName())
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list policies", err)
+	}
+
+	resp := &ssov1.ListPoliciesResponse{
+		Policies: make([]*ssov1.Policy, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		resp.Policies = append(resp.Policies, toProtoPolicy(policy))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) grantRole(ctx context.Context, userId int64, role string, appId int) (bool, error) {
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateRoleAssignment(userId, role, appId, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid grantRole request", slog.String("error", status.Convert(err).Message()))
+
+		return false, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	granted, err := s.accessService.GrantRole(ctx, authservice.GrantRoleDTO{
+		ActorId: actor.UserID,
+		UserId:  userId,
+		Role:    role,
+		AppId:   appId,
+	})
+	if err != nil {
+		return false, apierror.LogServiceError(ctx, log, "failed to grant role", err,
+			slog.Int64("actorId", actor.UserID),
+			slog.Int64("userId", userId),
+			slog.String("role", role),
+		)
+	}
+
+	log.Warn("role granted",
+		slog.Int64("actorId", actor.UserID),
+		slog.Int64("userId", userId),
+		slog.String("role", role),
+		slog.Int("appId", appId),
+	)
+
+	return granted, nil
+}
+
+func (s *serverAPI) revokeRole(ctx context.Context, userId int64, role string, appId int) (bool, error) {
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateRoleAssignment(userId, role, appId, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid revokeRole request", slog.String("error", status.Convert(err).Message()))
+
+		return false, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	revoked, err := s.accessService.RevokeRole(ctx, authservice.RevokeRoleDTO{
+		ActorId: actor.UserID,
+		UserId:  userId,
+		Role:    role,
+		AppId:   appId,
+	})
+	if err != nil {
+		return false, apierror.LogServiceError(ctx, log, "failed to revoke role", err,
+			slog.Int64("actorId", actor.UserID),
+			slog.Int64("userId", userId),
+			slog.String("role", role),
+		)
+	}
+
+	log.Warn("role revoked",
+		slog.Int64("actorId", actor.UserID),
+		slog.Int64("userId", userId),
+		slog.String("role", role),
+		slog.Int("appId", appId),
+	)
+
+	return revoked, nil
+}
+
+func toProtoAuditEvent(event entity.AuditEvent) *ssov1.AuditEvent {
+	return &ssov1.AuditEvent{
+		Id:        event.ID,
+		Type:      event.Type,
+		Success:   event.Success,
+		Reason:    event.Reason,
+		ActorId:   event.ActorID,
+		SubjectId: event.SubjectID,
+		AppId:     int32(event.AppID),
+		Ip:        event.IP,
+		RequestId: event.RequestID,
+		Details:   event.Details,
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+}
+
+func toProtoPolicy(policy entity.Policy) *ssov1.Policy {
+	return &ssov1.Policy{
+		Name:        policy.Name,
+		Version:     int32(policy.Version),
+		Description: policy.Description,
+		Action:      policy.Action,
+		AppId:       int32(policy.AppID),
+		Effect:      policy.Effect,
+		Expression:  policy.Expression,
+		DryRun:      policy.DryRun,
+		Enabled:     policy.Enabled,
+		CreatedBy:   policy.CreatedBy,
+		CreatedAt:   timestamppb.New(policy.CreatedAt),
+	}
+}
+
+func validateListAuditEventsRequest(req *ssov1.ListAuditEventsRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	pageSize := req.GetPageSize()
+	if err := validate.Var(pageSize, "gte=0"); err != nil {
+		violations = append(violations, apierror.Violation("page_size", "invalid_page_size"))
+	}
+
+	if err := req.GetFrom().CheckValid(); req.GetFrom() != nil && err != nil {
+		violations = append(violations, apierror.Violation("from", "invalid_from"))
+	}
+
+	if err := req.GetTo().CheckValid(); req.GetTo() != nil && err != nil {
+		violations = append(violations, apierror.Violation("to", "invalid_to"))
+	}
+
+	if req.GetFrom() != nil && req.GetTo() != nil && !req.GetFrom().AsTime().Before(req.GetTo().AsTime()) {
+		violations = append(violations, apierror.Violation("to", "from_after_to"))
+	}
+
+	return violations
+}
+
+func validateRoleAssignment(userId int64, role string, appId int, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	if err := validate.Var(role, "required"); err != nil {
+		violations = append(violations, apierror.Violation("role", "invalid_role"))
+	}
+
+	if err := validate.Var(appId, "gte=0"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	return violations
+}
+
+func validatePutPolicyRequest(req *ssov1.PutPolicyRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	name := req.GetName()
+	if err := validate.Var(name, "required,max=128"); err != nil {
+		violations = append(violations, apierror.Violation("name", "invalid_policy_name"))
+	}
+
+	action := req.GetAction()
+	if err := validate.Var(action, "required"); err != nil {
+		violations = append(violations, apierror.Violation("action", "invalid_action"))
+	}
+
+	appId := req.GetAppId()
+	if err := validate.Var(appId, "gte=0"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	effect := req.GetEffect()
+	if err := validate.Var(effect, "oneof=allow deny"); err != nil {
+		violations = append(violations, apierror.Violation("effect", "invalid_effect"))
+	}
+
+	expression := req.GetExpression()
+	if err := validate.Var(expression, "required"); err != nil {
+		violations = append(violations, apierror.Violation("expression", "invalid_expression"))
+	}
+
+	return violations
+}
@@ -0,0 +1,487 @@
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	appservice "github.com/4aykovski/grpc_auth_sso/internal/service/app"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/principal"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) CreateApp(
+	ctx context.Context,
+	req *ssov1.CreateAppRequest,
+) (*ssov1.CreateAppResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	name, redirectURIs, grantTypes, scopes := req.GetName(), req.GetRedirectUris(), req.GetGrantTypes(), req.GetScopes()
+	settings := entity.AppUpdate{Name: &name, RedirectURIs: &redirectURIs, GrantTypes: &grantTypes, Scopes: &scopes}
+
+	if violations := validateAppSettings(settings, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid createApp request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	app, secret, err := s.appService.CreateApp(ctx, appservice.CreateAppDTO{
+		ActorId:            actor.UserID,
+		Name:               req.GetName(),
+		RedirectURIs:       req.GetRedirectUris(),
+		GrantTypes:         req.GetGrantTypes(),
+		Scopes:             req.GetScopes(),
+		MembershipRequired: req.GetMembershipRequired(),
+		Public:             req.GetPublic(),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to create app", err, slog.String("app", req.GetName()))
+	}
+
+	log.Warn("app created", slog.Int64("actorId", actor.UserID), slog.Int("appId", app.ID), slog.String("app", app.Name))
+
+	return &ssov1.CreateAppResponse{
+		App:          toProtoApp(app),
+		ClientSecret: secret,
+	}, nil
+}
+
+func (s *serverAPI) UpdateApp(
+	ctx context.Context,
+	req *ssov1.UpdateAppRequest,
+) (*ssov1.UpdateAppResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	update, ok := appUpdate(req)
+
+	violations := validateAppSettings(update, s.validate)
+	if !ok {
+		violations = append(violations, apierror.Violation("update_mask", "invalid_update_mask"))
+	}
+	if err := s.validate.Var(req.GetAppId(), "gt=0"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+	if len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid updateApp request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	app, err := s.appService.UpdateApp(ctx, appservice.UpdateAppDTO{
+		ActorId: actor.UserID,
+		AppId:   int(req.GetAppId()),
+		Update:  update,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to update app", err, slog.Int("appId", int(req.GetAppId())))
+	}
+
+	log.Warn("app updated",
+		slog.Int64("actorId", actor.UserID),
+		slog.Int("appId", app.ID),
+		slog.Bool("disabled", app.Disabled),
+	)
+
+	return &ssov1.UpdateAppResponse{
+		App: toProtoApp(app),
+	}, nil
+}
+
+func (s *serverAPI) ListApps(
+	ctx context.Context,
+	req *ssov1.ListAppsRequest,
+) (*ssov1.ListAppsResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	apps, err := s.appService.ListApps(ctx)
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list apps", err)
+	}
+
+	resp := &ssov1.ListAppsResponse{
+		Apps: make([]*ssov1.App, 0, len(apps)),
+	}
+	for _, app := range apps {
+		resp.Apps = append(resp.Apps, toProtoApp(app))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) DeleteApp(
+	ctx context.Context,
+	req *ssov1.DeleteAppRequest,
+) (*ssov1.DeleteAppResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.validate.Var(req.GetAppId(), "gt=0"); err != nil {
+		err := apierror.BadRequest(ctx, []apierror.FieldViolation{apierror.Violation("app_id", "invalid_app_id")})
+
+		log.Info("invalid deleteApp request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	err := s.appService.DeleteApp(ctx, appservice.DeleteAppDTO{
+		ActorId: actor.UserID,
+		AppId:   int(req.GetAppId()),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to delete app", err, slog.Int("appId", int(req.GetAppId())))
+	}
+
+	log.Warn("app deleted", slog.Int64("actorId", actor.UserID), slog.Int("appId", int(req.GetAppId())))
+
+	return &ssov1.DeleteAppResponse{}, nil
+}
+
+func (s *serverAPI) RotateAppSecret(
+	ctx context.Context,
+	req *ssov1.RotateAppSecretRequest,
+) (*ssov1.RotateAppSecretResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.validate.Var(req.GetAppId(), "gt=0"); err != nil {
+		err := apierror.BadRequest(ctx, []apierror.FieldViolation{apierror.Violation("app_id", "invalid_app_id")})
+
+		log.Info("invalid rotateAppSecret request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	secret, err := s.appService.RotateAppSecret(ctx, appservice.RotateAppSecretDTO{
+		ActorId: actor.UserID,
+		AppId:   int(req.GetAppId()),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to rotate app secret", err, slog.Int("appId", int(req.GetAppId())))
+	}
+
+	log.Warn("app secret rotated", slog.Int64("actorId", actor.UserID), slog.Int("appId", int(req.GetAppId())))
+
+	return &ssov1.RotateAppSecretResponse{
+		ClientSecret: secret,
+	}, nil
+}
+
+func (s *serverAPI) AddAppMember(
+	ctx context.Context,
+	req *ssov1.AddAppMemberRequest,
+) (*ssov1.AddAppMemberResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateAppMember(req.GetAppId(), req.GetUserId(), s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid addAppMember request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	added, err := s.appService.AddMember(ctx, appservice.AppMemberDTO{
+		ActorId: actor.UserID,
+		AppId:   int(req.GetAppId()),
+		UserId:  req.GetUserId(),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to add app member", err,
+			slog.Int("appId", int(req.GetAppId())),
+			slog.Int64("userId", req.GetUserId()),
+		)
+	}
+
+	log.Warn("app member added",
+		slog.Int64("actorId", actor.UserID),
+		slog.Int("appId", int(req.GetAppId())),
+		slog.Int64("userId", req.GetUserId()),
+		slog.Bool("added", added),
+	)
+
+	return &ssov1.AddAppMemberResponse{
+		Added: added,
+	}, nil
+}
+
+func (s *serverAPI) RemoveAppMember(
+	ctx context.Context,
+	req *ssov1.RemoveAppMemberRequest,
+) (*ssov1.RemoveAppMemberResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateAppMember(req.GetAppId(), req.GetUserId(), s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid removeAppMember request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	actor, _ := principal.FromContext(ctx)
+
+	removed, err := s.appService.RemoveMember(ctx, appservice.AppMemberDTO{
+		ActorId: actor.UserID,
+		AppId:   int(req.GetAppId()),
+		UserId:  req.GetUserId(),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to remove app member", err,
+			slog.Int("appId", int(req.GetAppId())),
+			slog.Int64("userId", req.GetUserId()),
+		)
+	}
+
+	log.Warn("app member removed",
+		slog.Int64("actorId", actor.UserID),
+		slog.Int("appId", int(req.GetAppId())),
+		slog.Int64("userId", req.GetUserId()),
+		slog.Bool("removed", removed),
+	)
+
+	return &ssov1.RemoveAppMemberResponse{
+		Removed: removed,
+	}, nil
+}
+
+func (s *serverAPI) ListAppMembers(
+	ctx context.Context,
+	req *ssov1.ListAppMembersRequest,
+) (*ssov1.ListAppMembersResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if err := s.validate.Var(req.GetAppId(), "gt=0"); err != nil {
+		err := apierror.BadRequest(ctx, []apierror.FieldViolation{apierror.Violation("app_id", "invalid_app_id")})
+
+		log.Info("invalid listAppMembers request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	members, err := s.appService.ListMembers(ctx, int(req.GetAppId()))
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list app members", err, slog.Int("appId", int(req.GetAppId())))
+	}
+
+	resp := &ssov1.ListAppMembersResponse{
+		Members: make([]*ssov1.AppMember, 0, len(members)),
+	}
+	for _, member := range members {
+		resp.Members = append(resp.Members, &ssov1.AppMember{
+			UserId:    member.UserID,
+			Email:     member.Email,
+			CreatedAt: timestamppb.New(member.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// validateAppMember validates app and user of membership requests
+func validateAppMember(appId int32, userId int64, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	if err := validate.Var(appId, "gt=0"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	if err := validate.Var(userId, "gt=0"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	return violations
+}
+
+// scopePattern matches scope token of RFC 6749 section 3.3
+var scopePattern = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+
+func isInvalidScope(scope string) bool {
+	return !scopePattern.MatchString(scope)
+}
+
+func toProtoApp(app entity.App) *ssov1.App {
+	return &ssov1.App{
+		Id:                 int32(app.ID),
+		Name:               app.Name,
+		ClientId:           app.ClientID,
+		RedirectUris:       app.RedirectURIs,
+		GrantTypes:         app.GrantTypes,
+		Scopes:             app.Scopes,
+		MembershipRequired: app.MembershipRequired,
+		Disabled:           app.Disabled,
+		Public:             app.Public,
+		OwnerId:            app.OwnerID,
+		CreatedAt:          timestamppb.New(app.CreatedAt),
+		UpdatedAt:          timestamppb.New(app.UpdatedAt),
+	}
+}
+
+// appUpdate returns settings of app to change by request
+//
+// Fields listed in update mask are changed, if the mask is empty only fields with non default values are.
+// Reports false if the mask has unknown fields
+func appUpdate(req *ssov1.UpdateAppRequest) (entity.AppUpdate, bool) {
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = populatedAppFields(req)
+	}
+
+	var update entity.AppUpdate
+	for _, path := range paths {
+		switch path {
+		case "name":
+			name := req.GetName()
+			update.Name = &name
+		case "redirect_uris":
+			redirectURIs := req.GetRedirectUris()
+			update.RedirectURIs = &redirectURIs
+		case "grant_types":
+			grantTypes := req.GetGrantTypes()
+			update.GrantTypes = &grantTypes
+		case "scopes":
+			scopes := req.GetScopes()
+			update.Scopes = &scopes
+		case "membership_required":
+			membershipRequired := req.GetMembershipRequired()
+			update.MembershipRequired = &membershipRequired
+		case "disabled":
+			disabled := req.GetDisabled()
+			update.Disabled = &disabled
+		case "public":
+			public := req.GetPublic()
+			update.Public = &public
+		case "owner_id":
+			ownerID := req.GetOwnerId()
+			update.OwnerID = &ownerID
+		default:
+			return entity.AppUpdate{}, false
+		}
+	}
+
+	return update, true
+}
+
+// populatedAppFields returns names of app fields of request with non default values
+func populatedAppFields(req *ssov1.UpdateAppRequest) []string {
+	var fields []string
+	if req.GetName() != "" {
+		fields = append(fields, "name")
+	}
+	if len(req.GetRedirectUris()) > 0 {
+		fields = append(fields, "redirect_uris")
+	}
+	if len(req.GetGrantTypes()) > 0 {
+		fields = append(fields, "grant_types")
+	}
+	if len(req.GetScopes()) > 0 {
+		fields = append(fields, "scopes")
+	}
+	if req.GetMembershipRequired() {
+		fields = append(fields, "membership_required")
+	}
+	if req.GetDisabled() {
+		fields = append(fields, "disabled")
+	}
+	if req.GetPublic() {
+		fields = append(fields, "public")
+	}
+	if req.GetOwnerId() != 0 {
+		fields = append(fields, "owner_id")
+	}
+
+	return fields
+}
+
+// validateAppSettings validates settings set in app, nil settings are skipped
+func validateAppSettings(app entity.AppUpdate, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	if app.Name != nil {
+		if err := validate.Var(*app.Name, "required,max=128"); err != nil {
+			violations = append(violations, apierror.Violation("name", "invalid_app_name"))
+		}
+	}
+
+	if app.RedirectURIs != nil {
+		if err := validate.Var(*app.RedirectURIs, "unique"); err != nil || slices.ContainsFunc(*app.RedirectURIs, isInvalidRedirectURI) {
+			violations = append(violations, apierror.Violation("redirect_uris", "invalid_redirect_uri"))
+		}
+	}
+
+	if app.GrantTypes != nil {
+		if err := validate.Var(*app.GrantTypes, "unique,dive,oneof="+strings.Join(entity.GrantTypes, " ")); err != nil {
+			violations = append(violations, apierror.Violation("grant_types", "invalid_grant_type"))
+		}
+	}
+
+	if app.Scopes != nil {
+		if err := validate.Var(*app.Scopes, "unique"); err != nil || slices.ContainsFunc(*app.Scopes, isInvalidScope) {
+			violations = append(violations, apierror.Violation("scopes", "invalid_scope"))
+		}
+	}
+
+	if app.OwnerID != nil {
+		if err := validate.Var(*app.OwnerID, "gt=0"); err != nil {
+			violations = append(violations, apierror.Violation("owner_id", "invalid_user_id"))
+		}
+	}
+
+	return violations
+}
+
+// isInvalidRedirectURI reports whether uri isn't an absolute https url without fragment and user info,
+// http is allowed only for loopback hosts of native apps (RFC 8252 section 7.3)
+func isInvalidRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.User != nil || strings.Contains(uri, "#") {
+		return true
+	}
+
+	switch u.Scheme {
+	case "https":
+		return false
+	case "http":
+		return !isLoopback(u.Hostname())
+	default:
+		return true
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,221 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	appservice "github.com/4aykovski/grpc_auth_sso/internal/service/app"
+	auditservice "github.com/4aykovski/grpc_auth_sso/internal/service/audit"
+	authservice "github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	policyservice "github.com/4aykovski/grpc_auth_sso/internal/service/policy"
+	"github.com/4aykovski/grpc_auth_sso/pkg/i18n"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is a domain of ErrorInfo details
+const Domain = "sso"
+
+// Reasons of ErrorInfo details, they are part of API and must not be changed
+const (
+	ReasonInvalidArgument    = "INVALID_ARGUMENT"
+	ReasonInvalidCredentials = "INVALID_CREDENTIALS"
+	ReasonAppNotFound        = "APP_NOT_FOUND"
+	ReasonUserAlreadyExists  = "USER_ALREADY_EXISTS"
+	ReasonUserNotFound       = "USER_NOT_FOUND"
+	ReasonSessionNotFound    = "SESSION_NOT_FOUND"
+	ReasonNotAppMember       = "NOT_APP_MEMBER"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonInvalidToken       = "INVALID_TOKEN"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonRoleNotFound       = "ROLE_NOT_FOUND"
+	ReasonLastAdmin          = "LAST_ADMIN"
+	ReasonLoginDenied        = "LOGIN_DENIED"
+	ReasonInvalidPolicy      = "INVALID_POLICY"
+	ReasonPolicyConflict     = "POLICY_CONFLICT"
+	ReasonAppAlreadyExists   = "APP_ALREADY_EXISTS"
+	ReasonAppDisabled        = "APP_DISABLED"
+	ReasonPublicApp          = "PUBLIC_APP"
+	ReasonGrantNotAllowed    = "GRANT_NOT_ALLOWED"
+	ReasonInvalidPageToken   = "INVALID_PAGE_TOKEN"
+	ReasonClientNotAllowed   = "CLIENT_NOT_ALLOWED"
+	ReasonDeadlineExceeded   = "DEADLINE_EXCEEDED"
+	ReasonUnavailable        = "UNAVAILABLE"
+	ReasonInternal           = "INTERNAL"
+)
+
+// retryDelay is a delay clients are advised to wait before retrying transient failures
+const retryDelay = time.Second
+
+var serviceErrors = []struct {
+	err    error
+	code   codes.Code
+	reason string
+	key    string
+}{
+	{authservice.ErrInvalidCredentials, codes.Unauthenticated, ReasonInvalidCredentials, "invalid_credentials"},
+	// unknown app is reported as invalid credentials, so callers can't probe which apps exist
+	{authservice.ErrInvalidAppId, codes.Unauthenticated, ReasonInvalidCredentials, "invalid_credentials"},
+	{authservice.ErrNotAppMember, codes.PermissionDenied, ReasonNotAppMember, "not_app_member"},
+	{authservice.ErrUserAlreadyExists, codes.AlreadyExists, ReasonUserAlreadyExists, "user_already_exists"},
+	{authservice.ErrInvalidUserId, codes.InvalidArgument, ReasonUserNotFound, "invalid_user_id"},
+	{authservice.ErrInvalidSessionId, codes.NotFound, ReasonSessionNotFound, "session_not_found"},
+	{authservice.ErrAppNotFound, codes.NotFound, ReasonAppNotFound, "app_not_found"},
+	{authservice.ErrInvalidToken, codes.Unauthenticated, ReasonInvalidToken, "invalid_token"},
+	{authservice.ErrPermissionDenied, codes.PermissionDenied, ReasonPermissionDenied, "permission_denied"},
+	{authservice.ErrRoleNotFound, codes.NotFound, ReasonRoleNotFound, "role_not_found"},
+	{authservice.ErrLastAdmin, codes.FailedPrecondition, ReasonLastAdmin, "last_admin"},
+	{authservice.ErrLoginDenied, codes.PermissionDenied, ReasonLoginDenied, "login_denied"},
+	{policyservice.ErrInvalidPolicy, codes.InvalidArgument, ReasonInvalidPolicy, "invalid_policy"},
+	{policyservice.ErrPolicyConflict, codes.Aborted, ReasonPolicyConflict, "policy_conflict"},
+	{policyservice.ErrAppNotFound, codes.NotFound, ReasonAppNotFound, "app_not_found"},
+	{policyservice.ErrUserNotFound, codes.InvalidArgument, ReasonUserNotFound, "invalid_user_id"},
+	{authservice.ErrAppDisabled, codes.PermissionDenied, ReasonAppDisabled, "app_disabled"},
+	{authservice.ErrGrantNotAllowed, codes.PermissionDenied, ReasonGrantNotAllowed, "grant_not_allowed"},
+	{appservice.ErrAppNotFound, codes.NotFound, ReasonAppNotFound, "app_not_found"},
+	{appservice.ErrAppAlreadyExists, codes.AlreadyExists, ReasonAppAlreadyExists, "app_already_exists"},
+	{appservice.ErrPublicApp, codes.FailedPrecondition, ReasonPublicApp, "public_app"},
+	{appservice.ErrUserNotFound, codes.InvalidArgument, ReasonUserNotFound, "invalid_user_id"},
+	{auditservice.ErrInvalidPageToken, codes.InvalidArgument, ReasonInvalidPageToken, "invalid_page_token"},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, ReasonDeadlineExceeded, "deadline_exceeded"},
+}
+
+// FromServiceError converts service error to gRPC error with ErrorInfo and LocalizedMessage details
+//
+// Failures to reach the database are returned as codes.Unavailable with RetryInfo,
+// unknown errors as codes.Internal without exposing their text
+func FromServiceError(ctx context.Context, err error) error {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return New(ctx, e.code, e.reason, e.key)
+		}
+	}
+
+	if isUnavailable(err) {
+		return New(ctx, codes.Unavailable, ReasonUnavailable, "service_unavailable", &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(retryDelay),
+		})
+	}
+
+	return New(ctx, codes.Internal, ReasonInternal, "internal_error")
+}
+
+// IsServiceError reports whether err is a known service error, such errors are caused by the request
+func IsServiceError(err error) bool {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LogServiceError logs err with attrs and converts it with FromServiceError,
+// known service errors are logged at info level and other errors at error level
+func LogServiceError(ctx context.Context, log *slog.Logger, msg string, err error, attrs ...any) error {
+	attrs = append(attrs, slog.String("error", err.Error()))
+	if IsServiceError(err) {
+		log.Info(msg, attrs...)
+	} else {
+		log.Error(msg, attrs...)
+	}
+
+	return FromServiceError(ctx, err)
+}
+
+// New returns gRPC error with ErrorInfo of reason, LocalizedMessage and additional details
+//
+// key is a key of the message in i18n catalog, status message is always in i18n.Default language,
+// LocalizedMessage is in language of request
+func New(ctx context.Context, code codes.Code, reason string, key string, details ...protoadapt.MessageV1) error {
+	return newError(ctx, code, reason, i18n.Translate(i18n.Default, key), i18n.Localize(ctx, key), details...)
+}
+
+func newError(
+	ctx context.Context,
+	code codes.Code,
+	reason string,
+	message string,
+	localized string,
+	details ...protoadapt.MessageV1,
+) error {
+	details = append([]protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: Domain,
+		},
+		&errdetails.LocalizedMessage{
+			Locale:  i18n.FromContext(ctx).String(),
+			Message: localized,
+		},
+	}, details...)
+
+	st, err := status.New(code, message).WithDetails(details...)
+	if err != nil {
+		return status.Error(code, message)
+	}
+
+	return st.Err()
+}
+
+// FieldViolation describes invalid field of request
+type FieldViolation struct {
+	// Field is a name of proto field
+	Field string
+	// Key is a key of description in i18n catalog
+	Key string
+}
+
+func Violation(field string, key string) FieldViolation {
+	return FieldViolation{
+		Field: field,
+		Key:   key,
+	}
+}
+
+// BadRequest returns codes.InvalidArgument error with BadRequest details listing violations
+// with descriptions in language of request
+//
+// Status message joins descriptions in i18n.Default language with ";"
+func BadRequest(ctx context.Context, violations []FieldViolation) error {
+	var (
+		messages        = make([]string, 0, len(violations))
+		localized       = make([]string, 0, len(violations))
+		fieldViolations = make([]*errdetails.BadRequest_FieldViolation, 0, len(violations))
+	)
+	for _, v := range violations {
+		description := i18n.Localize(ctx, v.Key)
+
+		messages = append(messages, i18n.Translate(i18n.Default, v.Key))
+		localized = append(localized, description)
+		fieldViolations = append(fieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: description,
+		})
+	}
+
+	return newError(
+		ctx,
+		codes.InvalidArgument,
+		ReasonInvalidArgument,
+		strings.Join(messages, ";"),
+		strings.Join(localized, "; "),
+		&errdetails.BadRequest{FieldViolations: fieldViolations},
+	)
+}
+
+func isUnavailable(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
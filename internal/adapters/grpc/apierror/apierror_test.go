@@ -0,0 +1,121 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	authservice "github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	"github.com/4aykovski/grpc_auth_sso/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromServiceError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      codes.Code
+		reason    string
+		retryable bool
+	}{
+		{
+			name:   "invalid credentials",
+			err:    fmt.Errorf("can't login user: %w", authservice.ErrInvalidCredentials),
+			code:   codes.Unauthenticated,
+			reason: ReasonInvalidCredentials,
+		},
+		{
+			name:   "unknown app is reported as invalid credentials",
+			err:    fmt.Errorf("can't login user: %w", authservice.ErrInvalidAppId),
+			code:   codes.Unauthenticated,
+			reason: ReasonInvalidCredentials,
+		},
+		{
+			name:   "user already exists",
+			err:    fmt.Errorf("can't register user: %w", authservice.ErrUserAlreadyExists),
+			code:   codes.AlreadyExists,
+			reason: ReasonUserAlreadyExists,
+		},
+		{
+			name:      "database unavailable",
+			err:       fmt.Errorf("failed to get user: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}),
+			code:      codes.Unavailable,
+			reason:    ReasonUnavailable,
+			retryable: true,
+		},
+		{
+			name:   "unknown error",
+			err:    errors.New("something went wrong"),
+			code:   codes.Internal,
+			reason: ReasonInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(FromServiceError(context.Background(), tt.err))
+			assert.Equal(t, tt.code, st.Code())
+
+			var (
+				info  *errdetails.ErrorInfo
+				retry *errdetails.RetryInfo
+			)
+			for _, d := range st.Details() {
+				switch d := d.(type) {
+				case *errdetails.ErrorInfo:
+					info = d
+				case *errdetails.RetryInfo:
+					retry = d
+				}
+			}
+
+			require.NotNil(t, info)
+			assert.Equal(t, tt.reason, info.GetReason())
+			assert.Equal(t, Domain, info.GetDomain())
+			assert.Equal(t, tt.retryable, retry != nil)
+		})
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	ctx := i18n.WithLanguage(context.Background(), language.Russian)
+
+	err := BadRequest(ctx, []FieldViolation{
+		Violation("email", "invalid_email"),
+		Violation("password", "invalid_password"),
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "invalid email;invalid password", st.Message())
+
+	var (
+		badRequest *errdetails.BadRequest
+		localized  *errdetails.LocalizedMessage
+	)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			badRequest = d
+		case *errdetails.LocalizedMessage:
+			localized = d
+		}
+	}
+
+	require.NotNil(t, badRequest)
+	require.Len(t, badRequest.GetFieldViolations(), 2)
+	assert.Equal(t, "email", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "некорректный email", badRequest.GetFieldViolations()[0].GetDescription())
+	assert.Equal(t, "password", badRequest.GetFieldViolations()[1].GetField())
+
+	require.NotNil(t, localized)
+	assert.Equal(t, "ru", localized.GetLocale())
+	assert.Equal(t, "некорректный email; некорректный пароль", localized.GetMessage())
+}
@@ -2,24 +2,34 @@ package auth
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"log/slog"
 	"strconv"
-	"strings"
 
 	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
 	authservice "github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	policyservice "github.com/4aykovski/grpc_auth_sso/internal/service/policy"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
 	"github.com/go-playground/validator/v10"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AuthService interface {
-	Login(ctx context.Context, dto authservice.LoginDTO) (string, error)
+	Login(ctx context.Context, dto authservice.LoginDTO) (authservice.Tokens, error)
 	Register(ctx context.Context, dto authservice.RegisterDTO) (int64, error)
 	IsAdmin(ctx context.Context, dto authservice.IsAdminDTO) (bool, error)
+	ListSessions(ctx context.Context, dto authservice.ListSessionsDTO) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, dto authservice.RevokeSessionDTO) error
+	RevokeAllSessions(ctx context.Context, dto authservice.RevokeAllSessionsDTO) (int64, error)
+	CheckPermission(ctx context.Context, dto authservice.CheckPermissionDTO) (bool, error)
+	ListUserRoles(ctx context.Context, dto authservice.ListUserRolesDTO) ([]entity.Role, error)
+}
+
+type PolicyService interface {
+	Authorize(ctx context.Context, dto policyservice.AuthorizeDTO) (policyservice.Decision, error)
 }
 
 type serverAPI struct {
@@ -27,15 +37,17 @@ type serverAPI struct {
 
 	log *slog.Logger
 
-	validate    *validator.Validate
-	authService AuthService
+	validate      *validator.Validate
+	authService   AuthService
+	policyService PolicyService
 }
 
-func Register(gRPC *grpc.Server, log *slog.Logger, authService AuthService) {
+func Register(gRPC *grpc.Server, log *slog.Logger, authService AuthService, policyService PolicyService) {
 	ssov1.RegisterAuthServer(gRPC, &serverAPI{
-		log:         log,
-		validate:    validator.New(),
-		authService: authService,
+		log:           log,
+		validate:      validator.New(),
+		authService:   authService,
+		policyService: policyService,
 	})
 }
 
@@ -44,39 +56,31 @@ func (s *serverAPI) Login(
 	req *ssov1.LoginRequest,
 ) (*ssov1.LoginResponse, error) {
 
-	log := s.log.With(slog.String("method", "Login"))
+	log := logger.FromContext(ctx, s.log)
 
-	if err := validateLoginRequest(req, s.validate); err != nil {
-		var errMsgs []string
-		for _, err := range err {
-			errMsgs = append(errMsgs, err.Error())
-		}
+	if violations := validateLoginRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
 
-		log.Info("invalid login request", slog.String("error", strings.Join(errMsgs[:], ";")))
+		log.Info("invalid login request", slog.String("error", status.Convert(err).Message()))
 
-		return nil, status.Error(codes.InvalidArgument, strings.Join(errMsgs[:], ";"))
+		return nil, err
 	}
 
-	token, err := s.authService.Login(ctx, authservice.LoginDTO{
+	tokens, err := s.authService.Login(ctx, authservice.LoginDTO{
 		Email:    req.GetEmail(),
 		Password: req.GetPassword(),
 		AppId:    int(req.GetAppId()),
 	})
 	if err != nil {
-		if errors.Is(err, authservice.ErrInvalidCredentials) || errors.Is(err, authservice.ErrInvalidAppId) {
-			log.Info("invalid credentials")
-
-			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
-		}
-		log.Error("failed to login", slog.String("email", req.GetEmail()), slog.String("error", err.Error()))
-
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, apierror.LogServiceError(ctx, log, "failed to login", err, slog.Int("appId", int(req.GetAppId())))
 	}
 
-	log.Info("login successful")
+	log.Info("login successful", slog.Int64("sessionId", tokens.SessionId))
 
 	return &ssov1.LoginResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		SessionId:    tokens.SessionId,
 	}, nil
 }
 
@@ -85,17 +89,14 @@ func (s *serverAPI) Register(
 	req *ssov1.RegisterRequest,
 ) (*ssov1.RegisterResponse, error) {
 
-	log := s.log.With(slog.String("method", "Register"))
+	log := logger.FromContext(ctx, s.log)
 
-	if err := validateRegisterRequest(req, s.validate); err != nil {
-		var errMsgs []string
-		for _, err := range err {
-			errMsgs = append(errMsgs, err.Error())
-		}
+	if violations := validateRegisterRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
 
-		log.Info("invalid register request", slog.String("error", strings.Join(errMsgs[:], ";")))
+		log.Info("invalid register request", slog.String("error", status.Convert(err).Message()))
 
-		return nil, status.Error(codes.InvalidArgument, strings.Join(errMsgs[:], ";"))
+		return nil, err
 	}
 
 	userId, err := s.authService.Register(ctx, authservice.RegisterDTO{
@@ -103,14 +104,7 @@ func (s *serverAPI) Register(
 		Password: req.GetPassword(),
 	})
 	if err != nil {
-		if errors.Is(err, authservice.ErrUserAlreadyExists) {
-			log.Info("user already exists")
-
-			return nil, status.Error(codes.AlreadyExists, "user already exists")
-		}
-		log.Error("failed to register", slog.String("error", err.Error()))
-
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, apierror.LogServiceError(ctx, log, "failed to register", err)
 	}
 
 	log.Info("register successful")
@@ -125,17 +119,14 @@ func (s *serverAPI) IsAdmin(
 	req *ssov1.IsAdminRequest,
 ) (*ssov1.IsAdminResponse, error) {
 
-	log := s.log.With(slog.String("method", "IsAdmin"))
+	log := logger.FromContext(ctx, s.log)
 
-	if err := validateIsAdminRequest(req, s.validate); err != nil {
-		var errMsgs []string
-		for _, err := range err {
-			errMsgs = append(errMsgs, err.Error())
-		}
+	if violations := validateIsAdminRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
 
-		log.Info("invalid isAdmin request", slog.String("error", strings.Join(errMsgs[:], ";")))
+		log.Info("invalid isAdmin request", slog.String("error", status.Convert(err).Message()))
 
-		return nil, status.Error(codes.InvalidArgument, strings.Join(errMsgs[:], ";"))
+		return nil, err
 	}
 
 	userId := int(req.GetUserId())
@@ -144,14 +135,7 @@ func (s *serverAPI) IsAdmin(
 		UserId: userId,
 	})
 	if err != nil {
-		if errors.Is(err, authservice.ErrInvalidUserId) {
-			log.Info("invalid userId", slog.String("userId", strconv.Itoa(userId)))
-
-			return nil, status.Error(codes.InvalidArgument, "invalid userId")
-		}
-		log.Error("failed to check isAdmin", slog.String("userId", strconv.Itoa(userId)), slog.String("error", err.Error()))
-
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, apierror.LogServiceError(ctx, log, "failed to check isAdmin", err, slog.String("userId", strconv.Itoa(userId)))
 	}
 
 	log.Info("isAdmin check successful", slog.String("userId", strconv.Itoa(userId)), slog.Bool("isAdmin", isAdmin))
@@ -161,50 +145,380 @@ func (s *serverAPI) IsAdmin(
 	}, nil
 }
 
-func validateLoginRequest(req *ssov1.LoginRequest, validate *validator.Validate) []error {
-	var errs []error
+func (s *serverAPI) ListSessions(
+	ctx context.Context,
+	req *ssov1.ListSessionsRequest,
+) (*ssov1.ListSessionsResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateListSessionsRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid listSessions request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId := req.GetUserId()
+
+	sessions, err := s.authService.ListSessions(ctx, authservice.ListSessionsDTO{
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list sessions", err, slog.Int64("userId", userId))
+	}
+
+	resp := &ssov1.ListSessionsResponse{
+		Sessions: make([]*ssov1.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, toProtoSession(session))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) RevokeSession(
+	ctx context.Context,
+	req *ssov1.RevokeSessionRequest,
+) (*ssov1.RevokeSessionResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateRevokeSessionRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid revokeSession request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId, sessionId := req.GetUserId(), req.GetSessionId()
+
+	err := s.authService.RevokeSession(ctx, authservice.RevokeSessionDTO{
+		UserId:    userId,
+		SessionId: sessionId,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to revoke session", err, slog.Int64("userId", userId), slog.Int64("sessionId", sessionId))
+	}
+
+	log.Info("session revoked", slog.Int64("userId", userId), slog.Int64("sessionId", sessionId))
+
+	return &ssov1.RevokeSessionResponse{}, nil
+}
+
+func (s *serverAPI) RevokeAllSessions(
+	ctx context.Context,
+	req *ssov1.RevokeAllSessionsRequest,
+) (*ssov1.RevokeAllSessionsResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateRevokeAllSessionsRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid revokeAllSessions request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId := req.GetUserId()
+
+	revoked, err := s.authService.RevokeAllSessions(ctx, authservice.RevokeAllSessionsDTO{
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to revoke sessions", err, slog.Int64("userId", userId))
+	}
+
+	log.Info("sessions revoked", slog.Int64("userId", userId), slog.Int64("revoked", revoked))
+
+	return &ssov1.RevokeAllSessionsResponse{
+		Revoked: revoked,
+	}, nil
+}
+
+func (s *serverAPI) CheckPermission(
+	ctx context.Context,
+	req *ssov1.CheckPermissionRequest,
+) (*ssov1.CheckPermissionResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateCheckPermissionRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid checkPermission request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId, permission, appId := req.GetUserId(), req.GetPermission(), int(req.GetAppId())
+
+	allowed, err := s.authService.CheckPermission(ctx, authservice.CheckPermissionDTO{
+		UserId:     userId,
+		Permission: permission,
+		AppId:      appId,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to check permission", err,
+			slog.Int64("userId", userId),
+			slog.String("permission", permission),
+			slog.Int("appId", appId),
+		)
+	}
+
+	log.Info("permission check successful", slog.Int64("userId", userId), slog.String("permission", permission), slog.Int("appId", appId), slog.Bool("allowed", allowed))
+
+	return &ssov1.CheckPermissionResponse{
+		Allowed: allowed,
+	}, nil
+}
+
+func (s *serverAPI) ListUserRoles(
+	ctx context.Context,
+	req *ssov1.ListUserRolesRequest,
+) (*ssov1.ListUserRolesResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateListUserRolesRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid listUserRoles request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId := req.GetUserId()
+
+	roles, err := s.authService.ListUserRoles(ctx, authservice.ListUserRolesDTO{
+		AppId:  int(req.GetAppId()),
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to list user roles", err, slog.Int64("userId", userId))
+	}
+
+	resp := &ssov1.ListUserRolesResponse{
+		Roles: make([]*ssov1.Role, 0, len(roles)),
+	}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, toProtoRole(role))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) Authorize(
+	ctx context.Context,
+	req *ssov1.AuthorizeRequest,
+) (*ssov1.AuthorizeResponse, error) {
+
+	log := logger.FromContext(ctx, s.log)
+
+	if violations := validateAuthorizeRequest(req, s.validate); len(violations) > 0 {
+		err := apierror.BadRequest(ctx, violations)
+
+		log.Info("invalid authorize request", slog.String("error", status.Convert(err).Message()))
+
+		return nil, err
+	}
+
+	userId, appId, action := req.GetUserId(), int(req.GetAppId()), req.GetAction()
+
+	decision, err := s.policyService.Authorize(ctx, policyservice.AuthorizeDTO{
+		UserId:   userId,
+		AppId:    appId,
+		Action:   action,
+		Resource: req.GetResource(),
+	})
+	if err != nil {
+		return nil, apierror.LogServiceError(ctx, log, "failed to authorize", err, slog.Int64("userId", userId), slog.String("action", action))
+	}
+
+	log.Info("authorization decision",
+		slog.Int64("userId", userId),
+		slog.Int("appId", appId),
+		slog.String("action", action),
+		slog.Bool("allowed", decision.Allowed),
+		slog.String("policy", decision.Policy),
+	)
+
+	return &ssov1.AuthorizeResponse{
+		Allowed:       decision.Allowed,
+		Applicable:    decision.Applicable,
+		Policy:        decision.Policy,
+		PolicyVersion: int32(decision.Version),
+		DryRunAllowed: decision.DryRunAllowed,
+	}, nil
+}
+
+func toProtoRole(role entity.Role) *ssov1.Role {
+	return &ssov1.Role{
+		Id:          int32(role.ID),
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		AppId:       int32(role.AppID),
+	}
+}
+
+func toProtoSession(session entity.Session) *ssov1.Session {
+	return &ssov1.Session{
+		Id:         session.ID,
+		UserId:     session.UserID,
+		AppId:      int32(session.AppID),
+		Ip:         session.IP,
+		UserAgent:  session.UserAgent,
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		LastSeenAt: timestamppb.New(session.LastSeenAt),
+		ExpiresAt:  timestamppb.New(session.ExpiresAt),
+	}
+}
+
+func validateLoginRequest(req *ssov1.LoginRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
 
 	email := req.GetEmail()
 	if err := validate.Var(email, "required,email"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid email"))
+		violations = append(violations, apierror.Violation("email", "invalid_email"))
 	}
 
 	password := req.GetPassword()
 	if err := validate.Var(password, "required"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid password"))
+		violations = append(violations, apierror.Violation("password", "invalid_password"))
 	}
 
 	appId := req.GetAppId()
 	if err := validate.Var(appId, "required"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid app id"))
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
 	}
 
-	return errs
+	return violations
 }
 
-func validateRegisterRequest(req *ssov1.RegisterRequest, validate *validator.Validate) []error {
-	var errs []error
+func validateRegisterRequest(req *ssov1.RegisterRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
 
 	email := req.GetEmail()
 	if err := validate.Var(email, "required,email"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid email"))
+		violations = append(violations, apierror.Violation("email", "invalid_email"))
 	}
 
 	password := req.GetPassword()
 	if err := validate.Var(password, "required"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid password"))
+		violations = append(violations, apierror.Violation("password", "invalid_password"))
+	}
+
+	return violations
+}
+
+func validateIsAdminRequest(req *ssov1.IsAdminRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
 	}
 
-	return errs
+	return violations
 }
 
-func validateIsAdminRequest(req *ssov1.IsAdminRequest, validate *validator.Validate) []error {
-	var errs []error
+func validateListSessionsRequest(req *ssov1.ListSessionsRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
 
 	userId := req.GetUserId()
 	if err := validate.Var(userId, "required"); err != nil {
-		errs = append(errs, fmt.Errorf("invalid userId"))
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	return violations
+}
+
+func validateRevokeSessionRequest(req *ssov1.RevokeSessionRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	sessionId := req.GetSessionId()
+	if err := validate.Var(sessionId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("session_id", "invalid_session_id"))
+	}
+
+	return violations
+}
+
+func validateRevokeAllSessionsRequest(req *ssov1.RevokeAllSessionsRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	return violations
+}
+
+func validateCheckPermissionRequest(req *ssov1.CheckPermissionRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	permission := req.GetPermission()
+	if err := validate.Var(permission, "required"); err != nil {
+		violations = append(violations, apierror.Violation("permission", "invalid_permission"))
+	}
+
+	appId := req.GetAppId()
+	if err := validate.Var(appId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	return violations
+}
+
+func validateListUserRolesRequest(req *ssov1.ListUserRolesRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	appId := req.GetAppId()
+	if err := validate.Var(appId, "gte=0"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	return violations
+}
+
+func validateAuthorizeRequest(req *ssov1.AuthorizeRequest, validate *validator.Validate) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+
+	userId := req.GetUserId()
+	if err := validate.Var(userId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("user_id", "invalid_user_id"))
+	}
+
+	appId := req.GetAppId()
+	if err := validate.Var(appId, "required"); err != nil {
+		violations = append(violations, apierror.Violation("app_id", "invalid_app_id"))
+	}
+
+	action := req.GetAction()
+	if err := validate.Var(action, "required"); err != nil {
+		violations = append(violations, apierror.Violation("action", "invalid_action"))
 	}
 
-	return errs
+	return violations
 }
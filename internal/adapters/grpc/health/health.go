@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Checker serves grpc.health.v1 service reporting SERVING only while database is reachable
+type Checker struct {
+	log *slog.Logger
+
+	server   *health.Server
+	db       pinger
+	services []string
+
+	interval time.Duration
+	timeout  time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// New creates Checker of services
+//
+// Every service in services and the whole server ("") are reported NOT_SERVING until first successful ping
+func New(
+	log *slog.Logger,
+	db pinger,
+	interval time.Duration,
+	timeout time.Duration,
+	services ...string,
+) *Checker {
+	server := health.NewServer()
+
+	c := &Checker{
+		log:      log,
+		server:   server,
+		db:       db,
+		services: append([]string{""}, services...),
+		interval: interval,
+		timeout:  timeout,
+		stop:     make(chan struct{}),
+	}
+	c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+// Register registers health service in gRPC server, the same Checker can be registered in several servers
+func (c *Checker) Register(gRPC *grpc.Server) {
+	healthpb.RegisterHealthServer(gRPC, c.server)
+}
+
+// Run pings database every interval and updates serving status until Shutdown is called
+func (c *Checker) Run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	serving := false
+	for {
+		err := c.ping()
+		switch {
+		case err == nil && !serving:
+			c.log.Info("database is reachable, serving")
+			c.setStatus(healthpb.HealthCheckResponse_SERVING)
+			serving = true
+		case err != nil && serving:
+			c.log.Error("database is unreachable, not serving", slog.String("error", err.Error()))
+			c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+			serving = false
+		}
+
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops checks and reports NOT_SERVING for every service permanently
+func (c *Checker) Shutdown() {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	// statuses set by a check still in flight are ignored after shutdown
+	c.server.Shutdown()
+}
+
+func (c *Checker) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return c.db.PingContext(ctx)
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
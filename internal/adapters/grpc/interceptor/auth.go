@@ -0,0 +1,228 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/principal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+type PolicyKind int
+
+const (
+	// PolicyPublic allows anonymous calls
+	PolicyPublic PolicyKind = iota + 1
+	// PolicyAuthenticated requires valid access token of user or app
+	PolicyAuthenticated
+	// PolicySelf requires valid access token issued to user_id of request or to admin,
+	// or client credentials token with the policy scope issued to app_id of request
+	PolicySelf
+	// PolicyAdmin requires valid access token of user with admin permission granted globally
+	PolicyAdmin
+	// PolicyPermission requires valid access token of user with the permission in the app token was issued for
+	PolicyPermission
+	// PolicyGlobalPermission requires valid access token of user with the permission granted globally,
+	// roles granted in the app token was issued for don't count
+	PolicyGlobalPermission
+)
+
+// Policy declares who is allowed to call a method
+type Policy struct {
+	Kind PolicyKind
+	// Permission is a required permission of PolicyPermission and PolicyGlobalPermission
+	Permission string
+	// Scope lets apps with the scope call PolicySelf methods for themselves, apps are denied if empty
+	Scope string
+}
+
+func Public() Policy        { return Policy{Kind: PolicyPublic} }
+func Authenticated() Policy { return Policy{Kind: PolicyAuthenticated} }
+func Self() Policy          { return Policy{Kind: PolicySelf} }
+func Admin() Policy         { return Policy{Kind: PolicyAdmin} }
+
+func Permission(permission string) Policy {
+	return Policy{Kind: PolicyPermission, Permission: permission}
+}
+
+func GlobalPermission(permission string) Policy {
+	return Policy{Kind: PolicyGlobalPermission, Permission: permission}
+}
+
+// SelfOrScope is PolicySelf also allowing apps with the scope to call the method for themselves
+func SelfOrScope(scope string) Policy {
+	return Policy{Kind: PolicySelf, Scope: scope}
+}
+
+// Policies maps full method ("/package.Service/Method") or service ("package.Service") names to policies,
+// method policies take precedence over service ones
+type Policies map[string]Policy
+
+// Lookup returns policy of full method name
+func (p Policies) Lookup(fullMethod string) (Policy, bool) {
+	if policy, ok := p[fullMethod]; ok {
+		return policy, true
+	}
+
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	policy, ok := p[service]
+	return policy, ok
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, accessToken string) (entity.Principal, error)
+	HasPermission(ctx context.Context, userId int64, permission string, appId int) (bool, error)
+}
+
+// userRequest is a request of method acting on behalf of user
+type userRequest interface {
+	GetUserId() int64
+}
+
+// appRequest is a request of method acting in app
+type appRequest interface {
+	GetAppId() int32
+}
+
+// Auth authenticates bearer token from authorization metadata, stores its principal in context
+// and checks method policy, methods without policy are denied
+//
+// Must be chained after RequestMeta and Locale
+func Auth(log *slog.Logger, authenticator Authenticator, policies Policies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, log, authenticator, policies, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is Auth of streaming methods
+//
+// Request of a stream isn't known before the handler reads it, so PolicySelf requires admin permission
+func StreamAuth(log *slog.Logger, authenticator Authenticator, policies Policies) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), log, authenticator, policies, info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate checks access policy of method and returns ctx with principal of the request
+func authenticate(
+	ctx context.Context,
+	log *slog.Logger,
+	authenticator Authenticator,
+	policies Policies,
+	fullMethod string,
+	req any,
+) (context.Context, error) {
+	log = logger.FromContext(ctx, log).With(slog.String("method", fullMethod))
+
+	policy, ok := policies.Lookup(fullMethod)
+	if !ok {
+		log.Error("method has no access policy")
+
+		return nil, apierror.New(ctx, codes.PermissionDenied, apierror.ReasonPermissionDenied, "permission_denied")
+	}
+
+	if policy.Kind == PolicyPublic {
+		return ctx, nil
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		log.Info("missing access token")
+
+		return nil, apierror.New(ctx, codes.Unauthenticated, apierror.ReasonUnauthenticated, "unauthenticated")
+	}
+
+	p, err := authenticator.Authenticate(ctx, token)
+	if err != nil {
+		log.Info("invalid access token")
+
+		return nil, apierror.FromServiceError(ctx, err)
+	}
+
+	allowed, err := authorize(ctx, authenticator, policy, p, req)
+	if err != nil {
+		log.Error("failed to authorize request",
+			slog.Int64("userId", p.UserID),
+			slog.String("clientId", p.ClientID),
+			slog.String("error", err.Error()),
+		)
+
+		return nil, apierror.FromServiceError(ctx, err)
+	}
+
+	if !allowed {
+		log.Info("permission denied", slog.Int64("userId", p.UserID), slog.String("clientId", p.ClientID))
+
+		return nil, apierror.New(ctx, codes.PermissionDenied, apierror.ReasonPermissionDenied, "permission_denied")
+	}
+
+	return principal.WithPrincipal(ctx, p), nil
+}
+
+func authorize(ctx context.Context, authenticator Authenticator, policy Policy, p entity.Principal, req any) (bool, error) {
+	if p.IsApp() {
+		return authorizeApp(policy, p, req), nil
+	}
+
+	switch policy.Kind {
+	case PolicyAuthenticated:
+		return true, nil
+	case PolicySelf:
+		if r, ok := req.(userRequest); ok && r.GetUserId() == p.UserID {
+			return true, nil
+		}
+
+		return authenticator.HasPermission(ctx, p.UserID, entity.PermissionAdmin, 0)
+	case PolicyAdmin:
+		return authenticator.HasPermission(ctx, p.UserID, entity.PermissionAdmin, 0)
+	case PolicyPermission:
+		return authenticator.HasPermission(ctx, p.UserID, policy.Permission, p.AppID)
+	case PolicyGlobalPermission:
+		return authenticator.HasPermission(ctx, p.UserID, policy.Permission, 0)
+	default:
+		return false, errors.New("unknown access policy")
+	}
+}
+
+// authorizeApp checks policy of app principal, apps have no user and permissions,
+// so they may call only authenticated methods and self methods with their scope for themselves
+func authorizeApp(policy Policy, p entity.Principal, req any) bool {
+	switch policy.Kind {
+	case PolicyAuthenticated:
+		return true
+	case PolicySelf:
+		r, ok := req.(appRequest)
+		return ok && policy.Scope != "" && slices.Contains(p.Scopes, policy.Scope) && int(r.GetAppId()) == p.AppID
+	default:
+		return false
+	}
+}
+
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if scheme, token, ok := strings.Cut(v, " "); ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return ""
+}
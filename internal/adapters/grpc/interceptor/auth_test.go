@@ -0,0 +1,107 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testToken = "token"
+	// testAppToken is a client credentials token of app 1 with scope sso:authorize
+	testAppToken = "app-token"
+)
+
+// fakeAuthenticator accepts testToken and testAppToken issued for app 1 and grants permissions by app
+type fakeAuthenticator struct {
+	permissions map[int][]string
+}
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, accessToken string) (entity.Principal, error) {
+	switch accessToken {
+	case testToken:
+		return entity.Principal{UserID: 7, AppID: 1}, nil
+	case testAppToken:
+		return entity.Principal{AppID: 1, ClientID: "client", Scopes: []string{"sso:authorize"}}, nil
+	default:
+		return entity.Principal{}, status.Error(codes.Unauthenticated, "invalid token")
+	}
+}
+
+func (a fakeAuthenticator) HasPermission(_ context.Context, _ int64, permission string, appId int) (bool, error) {
+	return slices.Contains(a.permissions[appId], permission), nil
+}
+
+func TestAuth_Permission(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		permissions map[int][]string
+		code        codes.Code
+	}{
+		{name: "app permission", policy: Permission("audit:read"), permissions: map[int][]string{1: {"audit:read"}}, code: codes.OK},
+		{name: "global permission", policy: GlobalPermission("audit:read"), permissions: map[int][]string{0: {"audit:read"}}, code: codes.OK},
+		{name: "app permission isn't global", policy: GlobalPermission("audit:read"), permissions: map[int][]string{1: {"audit:read"}}, code: codes.PermissionDenied},
+		{name: "no permission", policy: Permission("audit:read"), permissions: nil, code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intercept := Auth(logger.NewDiscardLogger(), fakeAuthenticator{permissions: tt.permissions}, Policies{testMethod: tt.policy})
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+testToken))
+			_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(context.Context, any) (any, error) {
+					return "ok", nil
+				},
+			)
+
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestAuth_App(t *testing.T) {
+	// permissions of user 0 must not be granted to apps
+	permissions := map[int][]string{0: {entity.PermissionAdmin, "audit:read"}, 1: {"audit:read"}}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		req    any
+		code   codes.Code
+	}{
+		{name: "authenticated", policy: Authenticated(), code: codes.OK},
+		{name: "scope in own app", policy: SelfOrScope("sso:authorize"), req: &ssov1.AuthorizeRequest{AppId: 1}, code: codes.OK},
+		{name: "scope in another app", policy: SelfOrScope("sso:authorize"), req: &ssov1.AuthorizeRequest{AppId: 2}, code: codes.PermissionDenied},
+		{name: "missing scope", policy: SelfOrScope("orders:read"), req: &ssov1.AuthorizeRequest{AppId: 1}, code: codes.PermissionDenied},
+		{name: "self", policy: Self(), req: &ssov1.AuthorizeRequest{AppId: 1}, code: codes.PermissionDenied},
+		{name: "admin", policy: Admin(), code: codes.PermissionDenied},
+		{name: "permission", policy: Permission("audit:read"), code: codes.PermissionDenied},
+		{name: "global permission", policy: GlobalPermission("audit:read"), code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intercept := Auth(logger.NewDiscardLogger(), fakeAuthenticator{permissions: permissions}, Policies{testMethod: tt.policy})
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+testAppToken))
+			_, err := intercept(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(context.Context, any) (any, error) {
+					return "ok", nil
+				},
+			)
+
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
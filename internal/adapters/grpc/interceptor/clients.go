@@ -0,0 +1,33 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// AllowedClients lets only clients with verified certificate identity from allowed
+// call methods of services, other services are not restricted
+//
+// services are full service names, e.g. "package.Service".
+// Must be chained after RequestMeta
+func AllowedClients(services []string, allowed []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		service, _, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+		if !slices.Contains(services, service) {
+			return handler(ctx, req)
+		}
+
+		identity := requestmeta.FromContext(ctx).ClientIdentity
+		if identity == "" || !slices.Contains(allowed, identity) {
+			return nil, apierror.New(ctx, codes.PermissionDenied, apierror.ReasonClientNotAllowed, "client_not_allowed")
+		}
+
+		return handler(ctx, req)
+	}
+}
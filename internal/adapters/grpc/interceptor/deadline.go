@@ -0,0 +1,80 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Timeouts limits time a request is allowed to take, zero values mean no limit
+type Timeouts struct {
+	// Default is applied to requests without client deadline
+	Default time.Duration
+	// Max caps client deadlines which are further away
+	Max time.Duration
+}
+
+// Deadline applies server side deadline to every request, so handlers and
+// repository queries are cancelled when it is exceeded
+//
+// Timeouts of a method are looked up in methods by full method name ("/package.Service/Method")
+// or by short one ("Service/Method"), defaults are used for methods missing there.
+// Internal errors caused by exceeded deadline are reported as codes.DeadlineExceeded
+func Deadline(defaults Timeouts, methods map[string]Timeouts) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		timeouts := timeoutsOf(info.FullMethod, defaults, methods)
+
+		deadline, hasDeadline := ctx.Deadline()
+		switch {
+		case hasDeadline && timeouts.Max > 0 && time.Until(deadline) > timeouts.Max:
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeouts.Max)
+			defer cancel()
+		case !hasDeadline && timeouts.Default > 0:
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeouts.Default)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, req)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && status.Code(err) == codes.Internal {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		}
+
+		return resp, err
+	}
+}
+
+func timeoutsOf(fullMethod string, defaults Timeouts, methods map[string]Timeouts) Timeouts {
+	timeouts, ok := methods[fullMethod]
+	if !ok {
+		timeouts, ok = methods[shortMethod(fullMethod)]
+	}
+	if !ok {
+		return defaults
+	}
+
+	if timeouts.Default == 0 {
+		timeouts.Default = defaults.Default
+	}
+	if timeouts.Max == 0 {
+		timeouts.Max = defaults.Max
+	}
+
+	return timeouts
+}
+
+// shortMethod turns "/package.Service/Method" into "Service/Method"
+func shortMethod(fullMethod string) string {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return fullMethod
+	}
+
+	return service[strings.LastIndex(service, ".")+1:] + "/" + method
+}
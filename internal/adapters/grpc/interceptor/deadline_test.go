@@ -0,0 +1,170 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShortMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod string
+		expected   string
+	}{
+		{fullMethod: "/auth.Auth/Login", expected: "Auth/Login"},
+		{fullMethod: "/grpc.health.v1.Health/Check", expected: "Health/Check"},
+		{fullMethod: "/Auth/Login", expected: "Auth/Login"},
+		{fullMethod: "/malformed", expected: "/malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullMethod, func(t *testing.T) {
+			assert.Equal(t, tt.expected, shortMethod(tt.fullMethod))
+		})
+	}
+}
+
+func TestTimeoutsOf(t *testing.T) {
+	defaults := Timeouts{Default: time.Minute, Max: time.Hour}
+
+	tests := []struct {
+		name       string
+		fullMethod string
+		methods    map[string]Timeouts
+		expected   Timeouts
+	}{
+		{
+			name:       "no override",
+			fullMethod: "/auth.Auth/Login",
+			methods:    map[string]Timeouts{"Auth/Register": {Default: time.Second}},
+			expected:   defaults,
+		},
+		{
+			name:       "full method override",
+			fullMethod: "/auth.Auth/Login",
+			methods:    map[string]Timeouts{"/auth.Auth/Login": {Default: time.Second, Max: 2 * time.Second}},
+			expected:   Timeouts{Default: time.Second, Max: 2 * time.Second},
+		},
+		{
+			name:       "short method override",
+			fullMethod: "/auth.Auth/Login",
+			methods:    map[string]Timeouts{"Auth/Login": {Default: time.Second, Max: 2 * time.Second}},
+			expected:   Timeouts{Default: time.Second, Max: 2 * time.Second},
+		},
+		{
+			name:       "full method override takes precedence",
+			fullMethod: "/auth.Auth/Login",
+			methods: map[string]Timeouts{
+				"/auth.Auth/Login": {Default: time.Second},
+				"Auth/Login":       {Default: 3 * time.Second},
+			},
+			expected: Timeouts{Default: time.Second, Max: time.Hour},
+		},
+		{
+			name:       "missing values are taken from defaults",
+			fullMethod: "/auth.Auth/Login",
+			methods:    map[string]Timeouts{"Auth/Login": {Max: 2 * time.Minute}},
+			expected:   Timeouts{Default: time.Minute, Max: 2 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, timeoutsOf(tt.fullMethod, defaults, tt.methods))
+		})
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name string
+		// clientTimeout is a deadline of incoming request, none if zero
+		clientTimeout time.Duration
+		defaults      Timeouts
+		methods       map[string]Timeouts
+		// expected is an expected time left to deadline in handler, no deadline if zero
+		expected time.Duration
+	}{
+		{
+			name:     "default is applied without client deadline",
+			defaults: Timeouts{Default: time.Minute, Max: time.Hour},
+			expected: time.Minute,
+		},
+		{
+			name:     "no deadline without default",
+			defaults: Timeouts{Max: time.Hour},
+		},
+		{
+			name:          "shorter client deadline is kept",
+			clientTimeout: time.Second,
+			defaults:      Timeouts{Default: time.Minute, Max: time.Hour},
+			expected:      time.Second,
+		},
+		{
+			name:          "client deadline is capped",
+			clientTimeout: 2 * time.Hour,
+			defaults:      Timeouts{Default: time.Minute, Max: time.Hour},
+			expected:      time.Hour,
+		},
+		{
+			name:          "client deadline is capped by method override",
+			clientTimeout: time.Hour,
+			defaults:      Timeouts{Default: time.Minute, Max: time.Hour},
+			methods:       map[string]Timeouts{"Auth/Login": {Max: 10 * time.Second}},
+			expected:      10 * time.Second,
+		},
+		{
+			name:     "method override of default",
+			defaults: Timeouts{Default: time.Minute, Max: time.Hour},
+			methods:  map[string]Timeouts{"Auth/Login": {Default: 10 * time.Second}},
+			expected: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.clientTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.clientTimeout)
+				defer cancel()
+			}
+
+			intercept := Deadline(tt.defaults, tt.methods)
+
+			_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(ctx context.Context, _ any) (any, error) {
+					deadline, ok := ctx.Deadline()
+					if tt.expected == 0 {
+						assert.False(t, ok)
+						return nil, nil
+					}
+
+					require.True(t, ok)
+					assert.WithinDuration(t, time.Now().Add(tt.expected), deadline, time.Second)
+					return nil, nil
+				},
+			)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDeadline_ExceededInternalError(t *testing.T) {
+	intercept := Deadline(Timeouts{Default: time.Millisecond}, nil)
+
+	_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return nil, status.Error(codes.Internal, "query cancelled")
+		},
+	)
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
@@ -0,0 +1,25 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/pkg/i18n"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const headerAcceptLanguage = "accept-language"
+
+// Locale stores language negotiated from accept-language header in context,
+// i18n.Default is used if client didn't send the header or no supported language matches it
+func Locale() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var acceptLanguage string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			acceptLanguage = strings.Join(md.Get(headerAcceptLanguage), ",")
+		}
+
+		return handler(i18n.WithLanguage(ctx, i18n.Match(acceptLanguage)), req)
+	}
+}
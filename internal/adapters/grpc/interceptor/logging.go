@@ -0,0 +1,90 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logging stores request scoped logger in context and logs every finished request
+// with its status code and latency
+//
+// Trace and span ids are added to the logger if request is traced.
+// Must be chained after RequestMeta
+func Logging(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		reqLog := requestLogger(ctx, log, info.FullMethod)
+
+		start := time.Now()
+		resp, err := handler(logger.WithLogger(ctx, reqLog), req)
+
+		logFinished(ctx, reqLog, err, start)
+
+		return resp, err
+	}
+}
+
+// StreamLogging stores stream scoped logger in its context and logs every finished stream like Logging
+//
+// Must be chained after StreamRequestMeta
+func StreamLogging(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		reqLog := requestLogger(ctx, log, info.FullMethod)
+
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: logger.WithLogger(ctx, reqLog)})
+
+		logFinished(ctx, reqLog, err, start)
+
+		return err
+	}
+}
+
+// requestLogger returns logger of request to method with its id, peer and trace
+func requestLogger(ctx context.Context, log *slog.Logger, method string) *slog.Logger {
+	meta := requestmeta.FromContext(ctx)
+
+	reqLog := log.With(
+		slog.String("requestId", meta.RequestID),
+		slog.String("peer", meta.IP),
+		slog.String("method", method),
+	)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		reqLog = reqLog.With(
+			slog.String("traceId", sc.TraceID().String()),
+			slog.String("spanId", sc.SpanID().String()),
+		)
+	}
+
+	return reqLog
+}
+
+// logFinished logs finished request with status code of its error and latency since start
+func logFinished(ctx context.Context, reqLog *slog.Logger, err error, start time.Time) {
+	code := status.Code(err)
+
+	reqLog.Log(ctx, levelOf(code), "request finished",
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+	)
+}
+
+func levelOf(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.Unauthenticated, codes.PermissionDenied, codes.FailedPrecondition:
+		return slog.LevelInfo
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
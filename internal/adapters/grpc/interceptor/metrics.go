@@ -0,0 +1,37 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type requestObserver interface {
+	ObserveRequest(method string, code string, latency time.Duration)
+}
+
+// Metrics reports method, status code and latency of every request to observer
+func Metrics(observer requestObserver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		observer.ObserveRequest(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return resp, err
+	}
+}
+
+// StreamMetrics reports method, status code and duration of every stream to observer
+func StreamMetrics(observer requestObserver) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+
+		observer.ObserveRequest(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return err
+	}
+}
@@ -0,0 +1,47 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamMetrics(t *testing.T) {
+	m := metrics.New()
+	intercept := StreamMetrics(m)
+
+	err := intercept(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: testMethod},
+		func(any, grpc.ServerStream) error {
+			return status.Error(codes.Unavailable, "unavailable")
+		},
+	)
+	require.Error(t, err)
+
+	families, err := m.Registry.Gather()
+	require.NoError(t, err)
+
+	var requests float64
+	for _, family := range families {
+		if family.GetName() != "sso_grpc_requests_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == testMethod && labels["code"] == codes.Unavailable.String() {
+				requests = metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	assert.Equal(t, 1.0, requests)
+}
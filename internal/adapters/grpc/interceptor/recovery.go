@@ -0,0 +1,62 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type panicObserver interface {
+	ObservePanic(method string)
+}
+
+// Recovery converts panics of handlers into codes.Internal errors, so a single request can't crash the server
+//
+// Panic is logged with its stack and request id and reported to observer.
+// Panics of handler and of interceptors chained after Recovery are recovered, so it should be chained
+// right after Logging and Metrics, which see the resulting error, and before interceptors it protects
+func Recovery(log *slog.Logger, observer panicObserver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, log, observer, info.FullMethod, p)
+				resp = nil
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery converts panics of stream handlers into codes.Internal errors like Recovery
+func StreamRecovery(log *slog.Logger, observer panicObserver) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), log, observer, info.FullMethod, p)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs and reports recovered panic p of method and returns error of the request
+func recovered(ctx context.Context, log *slog.Logger, observer panicObserver, method string, p any) error {
+	logger.FromContext(ctx, log).Error("panic recovered",
+		slog.String("requestId", requestmeta.FromContext(ctx).RequestID),
+		slog.String("method", method),
+		slog.String("panic", fmt.Sprint(p)),
+		slog.String("stack", string(debug.Stack())),
+	)
+	observer.ObservePanic(method)
+
+	return apierror.New(ctx, codes.Internal, apierror.ReasonInternal, "internal_error")
+}
@@ -0,0 +1,137 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/metrics"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/auth.Auth/Login"
+
+func TestRecovery_Panic(t *testing.T) {
+	m := metrics.New()
+	intercept := Recovery(logger.NewDiscardLogger(), m)
+
+	resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(context.Context, any) (any, error) {
+			panic("boom")
+		},
+	)
+
+	assert.Nil(t, resp)
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1.0, panicsOf(t, m, testMethod))
+}
+
+func TestRecovery_NoPanic(t *testing.T) {
+	m := metrics.New()
+	intercept := Recovery(logger.NewDiscardLogger(), m)
+
+	resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(context.Context, any) (any, error) {
+			return "ok", nil
+		},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Zero(t, panicsOf(t, m, testMethod))
+}
+
+func TestRecovery_PanicOfChainedInterceptor(t *testing.T) {
+	m := metrics.New()
+	intercept := Recovery(logger.NewDiscardLogger(), m)
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	// interceptors chained after Recovery run inside of its handler
+	panicking := func(context.Context, any, *grpc.UnaryServerInfo, grpc.UnaryHandler) (any, error) {
+		panic("boom")
+	}
+
+	_, err := intercept(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return panicking(ctx, req, info, nil)
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1.0, panicsOf(t, m, testMethod))
+}
+
+func TestStreamRecovery_Panic(t *testing.T) {
+	m := metrics.New()
+	intercept := StreamRecovery(logger.NewDiscardLogger(), m)
+
+	err := intercept(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: testMethod},
+		func(any, grpc.ServerStream) error {
+			panic("boom")
+		},
+	)
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1.0, panicsOf(t, m, testMethod))
+}
+
+func TestStreamRecovery_NoPanic(t *testing.T) {
+	m := metrics.New()
+	intercept := StreamRecovery(logger.NewDiscardLogger(), m)
+
+	err := intercept(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: testMethod},
+		func(any, grpc.ServerStream) error {
+			return nil
+		},
+	)
+
+	require.NoError(t, err)
+	assert.Zero(t, panicsOf(t, m, testMethod))
+}
+
+// testStream is a server stream with given context recording headers sent,
+// its other methods mustn't be called
+type testStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// panicsOf returns value of panics counter of method
+func panicsOf(t *testing.T, m *metrics.Metrics, method string) float64 {
+	t.Helper()
+
+	families, err := m.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "sso_grpc_panics_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" && label.GetValue() == method {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
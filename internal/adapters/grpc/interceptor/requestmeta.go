@@ -0,0 +1,149 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	headerRequestID = "x-request-id"
+	headerUserAgent = "user-agent"
+
+	// headers set by HTTP gateways for proxied requests
+	headerForwardedFor       = "x-forwarded-for"
+	headerForwardedUserAgent = "x-forwarded-user-agent"
+	inProcessNetwork         = "bufconn"
+
+	maxRequestIDLen = 128
+)
+
+// RequestMeta stores client IP, user agent, request id and client certificate identity
+// of incoming request in context
+//
+// Request id is taken from x-request-id header or generated if client didn't send it,
+// and is echoed back in x-request-id response header
+func RequestMeta() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		meta := requestMeta(ctx)
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(headerRequestID, meta.RequestID))
+
+		return handler(requestmeta.WithMeta(ctx, meta), req)
+	}
+}
+
+// StreamRequestMeta stores metadata of incoming stream in its context like RequestMeta
+func StreamRequestMeta() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		meta := requestMeta(ss.Context())
+
+		_ = ss.SetHeader(metadata.Pairs(headerRequestID, meta.RequestID))
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: requestmeta.WithMeta(ss.Context(), meta)})
+	}
+}
+
+// requestMeta returns metadata of incoming request, generating request id if client didn't send valid one
+func requestMeta(ctx context.Context) requestmeta.Meta {
+	var meta requestmeta.Meta
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			meta.IP = peerIP(p.Addr)
+		}
+		meta.ClientIdentity = clientIdentity(p.AuthInfo)
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		meta.RequestID = firstValue(md, headerRequestID)
+		meta.UserAgent = firstValue(md, headerUserAgent)
+
+		if isInProcess(ctx) {
+			if ip := lastForwardedFor(md); ip != "" {
+				meta.IP = ip
+			}
+			if userAgent := firstValue(md, headerForwardedUserAgent); userAgent != "" {
+				meta.UserAgent = userAgent
+			}
+		}
+	}
+
+	if meta.RequestID == "" || len(meta.RequestID) > maxRequestIDLen {
+		meta.RequestID = newRequestID()
+	}
+
+	return meta
+}
+
+// clientIdentity returns identity of verified client certificate: its first URI SAN
+// (e.g. SPIFFE id), first DNS SAN or common name in this order of preference
+func clientIdentity(authInfo credentials.AuthInfo) string {
+	tlsInfo, ok := authInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	switch {
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	default:
+		return cert.Subject.CommonName
+	}
+}
+
+// isInProcess reports whether request came from in-process listener, i.e. from HTTP gateways,
+// only such requests are trusted to carry client address in headers
+func isInProcess(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+
+	return ok && p.Addr != nil && p.Addr.Network() == inProcessNetwork
+}
+
+// lastForwardedFor returns address of the gateway's direct client,
+// addresses before it are set by the client and can't be trusted
+func lastForwardedFor(md metadata.MD) string {
+	values := md.Get(headerForwardedFor)
+	if len(values) == 0 {
+		return ""
+	}
+
+	addrs := strings.Split(values[len(values)-1], ",")
+
+	return strings.TrimSpace(addrs[len(addrs)-1])
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func peerIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamRequestMetaAndLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(headerRequestID, "stream-request"))
+	ss := &testStream{ctx: ctx}
+	info := &grpc.StreamServerInfo{FullMethod: testMethod}
+
+	requestMeta, logging := StreamRequestMeta(), StreamLogging(log)
+
+	err := requestMeta(nil, ss, info, func(srv any, ss grpc.ServerStream) error {
+		return logging(srv, ss, info, func(_ any, ss grpc.ServerStream) error {
+			assert.Equal(t, "stream-request", requestmeta.FromContext(ss.Context()).RequestID)
+			logger.FromContext(ss.Context(), logger.NewDiscardLogger()).Info("handling stream")
+
+			return status.Error(codes.NotFound, "not found")
+		})
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, []string{"stream-request"}, ss.header.Get(headerRequestID))
+	assert.Contains(t, buf.String(), `"msg":"handling stream","requestId":"stream-request"`)
+	assert.Contains(t, buf.String(), `"msg":"request finished","requestId":"stream-request"`)
+	assert.Contains(t, buf.String(), `"code":"NotFound"`)
+}
@@ -0,0 +1,550 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	authservice "github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/manager/token"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+)
+
+const (
+	headerRequestID = "X-Request-Id"
+	maxRequestIDLen = 128
+
+	csrfCookie    = "sso_csrf"
+	csrfTokenSize = 32
+
+	responseTypeCode = "code"
+	challengeS256    = "S256"
+	tokenTypeBearer  = "Bearer"
+
+	// jwksMaxAge is a time clients may cache public keys for
+	jwksMaxAge = 5 * time.Minute
+)
+
+// error codes of RFC 6749
+const (
+	errInvalidRequest          = "invalid_request"
+	errInvalidClient           = "invalid_client"
+	errInvalidGrant            = "invalid_grant"
+	errInvalidScope            = "invalid_scope"
+	errUnauthorizedClient      = "unauthorized_client"
+	errUnsupportedGrantType    = "unsupported_grant_type"
+	errUnsupportedResponseType = "unsupported_response_type"
+	errAccessDenied            = "access_denied"
+	errServerError             = "server_error"
+)
+
+var (
+	// codeChallengePattern matches base64url encoded SHA-256 digest
+	codeChallengePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+	// codeVerifierPattern matches code verifier of RFC 7636 section 4.1
+	codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+// AuthService authenticates users and issues tokens of OAuth clients
+type AuthService interface {
+	GetClient(ctx context.Context, clientId string, redirectURI string) (entity.App, error)
+	IssueAuthorizationCode(ctx context.Context, dto authservice.IssueCodeDTO) (string, error)
+	ExchangeAuthorizationCode(ctx context.Context, dto authservice.ExchangeCodeDTO) (authservice.Tokens, error)
+	IssueClientToken(ctx context.Context, dto authservice.ClientCredentialsDTO) (authservice.ClientToken, error)
+}
+
+type handler struct {
+	log         *slog.Logger
+	authService AuthService
+	jwks        token.JWKS
+}
+
+// New returns HTTP handler of OAuth 2.0 authorization server
+//
+// GET /authorize renders login page of authorization code flow, POST /authorize checks credentials
+// and redirects user back to the client with authorization code. Only PKCE with S256 method is supported.
+// POST /token exchanges authorization code for access and refresh tokens, or issues access token
+// of the app itself to client authenticated with its client id and secret (client credentials grant).
+// GET /.well-known/jwks.json publishes jwks, public keys access tokens are verified with
+func New(log *slog.Logger, authService AuthService, jwks token.JWKS) http.Handler {
+	h := &handler{
+		log:         log,
+		authService: authService,
+		jwks:        jwks,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /authorize", h.authorizePage)
+	mux.HandleFunc("POST /authorize", h.authorize)
+	mux.HandleFunc("POST /token", h.token)
+	mux.HandleFunc("GET /.well-known/jwks.json", h.keys)
+
+	return h.requestMeta(mux)
+}
+
+type authorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func parseAuthorizeRequest(values url.Values) authorizeRequest {
+	return authorizeRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		State:               values.Get("state"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+	}
+}
+
+type loginPage struct {
+	authorizeRequest
+	AppName   string
+	CSRFToken string
+	Email     string
+	Error     string
+}
+
+func (h *handler) authorizePage(w http.ResponseWriter, r *http.Request) {
+	req := parseAuthorizeRequest(r.URL.Query())
+
+	app, ok := h.checkAuthorizeRequest(w, r, req)
+	if !ok {
+		return
+	}
+
+	csrfToken, err := newToken(csrfTokenSize)
+	if err != nil {
+		logger.FromContext(r.Context(), h.log).Error("failed to generate csrf token", slog.String("error", err.Error()))
+		redirectError(w, r, req, errServerError, "")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    csrfToken,
+		Path:     "/authorize",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	renderLogin(w, http.StatusOK, loginPage{
+		authorizeRequest: req,
+		AppName:          app.Name,
+		CSRFToken:        csrfToken,
+	})
+}
+
+func (h *handler) authorize(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	if err := r.ParseForm(); err != nil {
+		renderError(w, http.StatusBadRequest, "Malformed request.")
+		return
+	}
+
+	req := parseAuthorizeRequest(r.PostForm)
+
+	if !checkCSRF(r) {
+		log.Info("csrf token mismatch", slog.String("clientId", req.ClientID))
+		renderError(w, http.StatusForbidden, "Sign in session expired, please start again from the application.")
+		return
+	}
+
+	app, ok := h.checkAuthorizeRequest(w, r, req)
+	if !ok {
+		return
+	}
+
+	email := r.PostForm.Get("email")
+
+	code, err := h.authService.IssueAuthorizationCode(r.Context(), authservice.IssueCodeDTO{
+		Email:         email,
+		Password:      r.PostForm.Get("password"),
+		ClientId:      req.ClientID,
+		RedirectURI:   req.RedirectURI,
+		CodeChallenge: req.CodeChallenge,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrInvalidCredentials):
+			log.Info("invalid credentials", slog.Int("appId", app.ID))
+
+			csrfToken, _ := r.Cookie(csrfCookie)
+			renderLogin(w, http.StatusUnauthorized, loginPage{
+				authorizeRequest: req,
+				AppName:          app.Name,
+				CSRFToken:        csrfToken.Value,
+				Email:            email,
+				Error:            "Invalid email or password.",
+			})
+		case errors.Is(err, authservice.ErrGrantNotAllowed):
+			log.Info("authorization code grant is not allowed", slog.Int("appId", app.ID))
+			redirectError(w, r, req, errUnauthorizedClient, "")
+		case errors.Is(err, authservice.ErrNotAppMember),
+			errors.Is(err, authservice.ErrLoginDenied),
+			errors.Is(err, authservice.ErrInvalidClient):
+			log.Info("login is denied", slog.Int("appId", app.ID), slog.String("error", err.Error()))
+			redirectError(w, r, req, errAccessDenied, "")
+		default:
+			log.Error("failed to issue authorization code", slog.Int("appId", app.ID), slog.String("error", err.Error()))
+			redirectError(w, r, req, errServerError, "")
+		}
+
+		return
+	}
+
+	log.Info("authorization code issued", slog.Int("appId", app.ID))
+
+	redirect(w, r, req, url.Values{"code": {code}})
+}
+
+// checkAuthorizeRequest validates authorization request and returns the client app
+//
+// Errors of client and redirect uri are rendered to user, as redirect uri can't be trusted,
+// other errors are returned to the client through redirect uri
+func (h *handler) checkAuthorizeRequest(w http.ResponseWriter, r *http.Request, req authorizeRequest) (entity.App, bool) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	app, err := h.authService.GetClient(r.Context(), req.ClientID, req.RedirectURI)
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrInvalidClient):
+			log.Info("invalid client", slog.String("clientId", req.ClientID))
+			renderError(w, http.StatusBadRequest, "Unknown or disabled application.")
+		case errors.Is(err, authservice.ErrInvalidRedirectURI):
+			log.Info("redirect uri is not registered", slog.String("clientId", req.ClientID), slog.String("redirectUri", req.RedirectURI))
+			renderError(w, http.StatusBadRequest, "Redirect URI is not registered for the application.")
+		default:
+			log.Error("failed to get client", slog.String("clientId", req.ClientID), slog.String("error", err.Error()))
+			renderError(w, http.StatusInternalServerError, "Internal error, please try again later.")
+		}
+
+		return entity.App{}, false
+	}
+
+	if req.ResponseType != responseTypeCode {
+		redirectError(w, r, req, errUnsupportedResponseType, "only code response type is supported")
+		return entity.App{}, false
+	}
+
+	if !app.AllowsGrant(entity.GrantAuthorizationCode) {
+		redirectError(w, r, req, errUnauthorizedClient, "")
+		return entity.App{}, false
+	}
+
+	if req.CodeChallengeMethod != challengeS256 || !codeChallengePattern.MatchString(req.CodeChallenge) {
+		redirectError(w, r, req, errInvalidRequest, "code_challenge with S256 code_challenge_method is required")
+		return entity.App{}, false
+	}
+
+	return app, true
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (h *handler) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, errInvalidRequest, "malformed request")
+		return
+	}
+
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case entity.GrantAuthorizationCode:
+		h.exchangeCode(w, r)
+	case entity.GrantClientCredentials:
+		h.issueClientToken(w, r)
+	case "":
+		writeTokenError(w, http.StatusBadRequest, errInvalidRequest, "grant_type is required")
+	default:
+		writeTokenError(w, http.StatusBadRequest, errUnsupportedGrantType, "")
+	}
+}
+
+func (h *handler) exchangeCode(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	clientID, clientSecret, basic := clientCredentials(r)
+
+	code := r.PostForm.Get("code")
+	redirectURI := r.PostForm.Get("redirect_uri")
+	codeVerifier := r.PostForm.Get("code_verifier")
+	if clientID == "" || code == "" || redirectURI == "" || !codeVerifierPattern.MatchString(codeVerifier) {
+		writeTokenError(w, http.StatusBadRequest, errInvalidRequest, "client_id, code, redirect_uri and code_verifier are required")
+		return
+	}
+
+	tokens, err := h.authService.ExchangeAuthorizationCode(r.Context(), authservice.ExchangeCodeDTO{
+		ClientId:     clientID,
+		ClientSecret: clientSecret,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrInvalidClient):
+			log.Info("invalid client", slog.String("clientId", clientID))
+			if basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="sso"`)
+			}
+			writeTokenError(w, http.StatusUnauthorized, errInvalidClient, "")
+		case errors.Is(err, authservice.ErrGrantNotAllowed):
+			log.Info("authorization code grant is not allowed", slog.String("clientId", clientID))
+			writeTokenError(w, http.StatusBadRequest, errUnauthorizedClient, "")
+		case errors.Is(err, authservice.ErrInvalidGrant):
+			log.Info("invalid authorization code", slog.String("clientId", clientID))
+			writeTokenError(w, http.StatusBadRequest, errInvalidGrant, "")
+		default:
+			log.Error("failed to exchange authorization code", slog.String("clientId", clientID), slog.String("error", err.Error()))
+			writeTokenError(w, http.StatusInternalServerError, errServerError, "")
+		}
+
+		return
+	}
+
+	log.Info("authorization code exchanged", slog.String("clientId", clientID), slog.Int64("sessionId", tokens.SessionId))
+
+	writeToken(w, tokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(tokens.AccessTokenTTL.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (h *handler) issueClientToken(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	clientID, clientSecret, basic := clientCredentials(r)
+	if clientID == "" || clientSecret == "" {
+		writeTokenError(w, http.StatusBadRequest, errInvalidRequest, "client_id and client_secret are required")
+		return
+	}
+
+	token, err := h.authService.IssueClientToken(r.Context(), authservice.ClientCredentialsDTO{
+		ClientId:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       strings.Fields(r.PostForm.Get("scope")),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrInvalidClient):
+			log.Info("invalid client", slog.String("clientId", clientID))
+			if basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="sso"`)
+			}
+			writeTokenError(w, http.StatusUnauthorized, errInvalidClient, "")
+		case errors.Is(err, authservice.ErrGrantNotAllowed):
+			log.Info("client credentials grant is not allowed", slog.String("clientId", clientID))
+			writeTokenError(w, http.StatusBadRequest, errUnauthorizedClient, "")
+		case errors.Is(err, authservice.ErrInvalidScope):
+			log.Info("scope is not granted", slog.String("clientId", clientID), slog.String("scope", r.PostForm.Get("scope")))
+			writeTokenError(w, http.StatusBadRequest, errInvalidScope, "")
+		default:
+			log.Error("failed to issue client token", slog.String("clientId", clientID), slog.String("error", err.Error()))
+			writeTokenError(w, http.StatusInternalServerError, errServerError, "")
+		}
+
+		return
+	}
+
+	log.Info("client token issued", slog.String("clientId", clientID))
+
+	writeToken(w, tokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   tokenTypeBearer,
+		ExpiresIn:   int64(token.AccessTokenTTL.Seconds()),
+		Scope:       strings.Join(token.Scopes, " "),
+	})
+}
+
+func (h *handler) keys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(jwksMaxAge.Seconds())))
+	_ = json.NewEncoder(w).Encode(h.jwks)
+}
+
+// clientCredentials returns client id and secret from HTTP basic authentication or request body
+// and reports whether basic authentication was used
+func clientCredentials(r *http.Request) (string, string, bool) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		// credentials are form encoded before basic authentication, RFC 6749 section 2.3.1
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			id = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(secret); err == nil {
+			secret = unescaped
+		}
+
+		return id, secret, true
+	}
+
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"), false
+}
+
+// checkCSRF compares csrf token of login form with the cookie set with login page
+func checkCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get("csrf_token"))) == 1
+}
+
+// redirect sends user back to redirect uri of the client with params and state of the request
+func redirect(w http.ResponseWriter, r *http.Request, req authorizeRequest, params url.Values) {
+	u, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "Invalid redirect URI.")
+		return
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	u.RawQuery = query.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, req authorizeRequest, code string, description string) {
+	params := url.Values{"error": {code}}
+	if description != "" {
+		params.Set("error_description", description)
+	}
+
+	redirect(w, r, req, params)
+}
+
+func renderLogin(w http.ResponseWriter, status int, page loginPage) {
+	setPageHeaders(w)
+	w.WriteHeader(status)
+	_ = templates.ExecuteTemplate(w, "login.html", page)
+}
+
+func renderError(w http.ResponseWriter, status int, message string) {
+	setPageHeaders(w)
+	w.WriteHeader(status)
+	_ = templates.ExecuteTemplate(w, "error.html", message)
+}
+
+// setPageHeaders forbids caching and framing of pages
+func setPageHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+}
+
+func writeToken(w http.ResponseWriter, resp tokenResponse) {
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code string, description string) {
+	writeJSON(w, status, errorResponse{Error: code, ErrorDescription: description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// requestMeta stores client IP, user agent and request id in context along with request scoped logger
+//
+// Request id is taken from X-Request-Id header or generated and is echoed back in the response
+func (h *handler) requestMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := requestmeta.Meta{
+			RequestID: r.Header.Get(headerRequestID),
+			IP:        remoteIP(r.RemoteAddr),
+			UserAgent: r.UserAgent(),
+		}
+		if meta.RequestID == "" || len(meta.RequestID) > maxRequestIDLen {
+			meta.RequestID = newRequestID()
+		}
+		w.Header().Set(headerRequestID, meta.RequestID)
+
+		reqLog := h.log.With(
+			slog.String("requestId", meta.RequestID),
+			slog.String("peer", meta.IP),
+			slog.String("path", r.URL.Path),
+		)
+
+		ctx := requestmeta.WithMeta(r.Context(), meta)
+		ctx = logger.WithLogger(ctx, reqLog)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func newToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
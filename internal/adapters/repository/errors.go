@@ -6,5 +6,15 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 
-	ErrAppNotFound = errors.New("app not found")
+	ErrAppNotFound      = errors.New("app not found")
+	ErrAppAlreadyExists = errors.New("app already exists")
+
+	ErrSessionNotFound = errors.New("session not found")
+
+	ErrRoleNotFound = errors.New("role not found")
+	ErrLastAdmin    = errors.New("last admin can't be revoked")
+
+	ErrPolicyConflict = errors.New("policy was changed concurrently")
+
+	ErrCodeNotFound = errors.New("authorization code not found")
 )
@@ -9,8 +9,12 @@ import (
 	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
 	"github.com/4aykovski/grpc_auth_sso/internal/entity"
 	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"github.com/lib/pq"
 )
 
+const appColumns = "id, name, membership_required, client_id, redirect_uris, grant_types, scopes, disabled, public, COALESCE(owner_id, 0), created_at, updated_at"
+
 type AppRepository struct {
 	db *postgres.Db
 }
@@ -21,15 +25,172 @@ func NewAppRepository(db *postgres.Db) *AppRepository {
 	}
 }
 
-func (r *AppRepository) GetApp(ctx context.Context, id int) (entity.App, error) {
-	stmt, err := r.db.Prepare("SELECT * FROM apps WHERE id = $1")
+// GetApp returns app by id
+func (r *AppRepository) GetApp(ctx context.Context, id int) (_ entity.App, err error) {
+	const query = "SELECT " + appColumns + " FROM apps WHERE id = $1"
+
+	ctx, span := startSpan(ctx, "AppRepository.GetApp", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return entity.App{}, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	var app entity.App
-	err = stmt.QueryRowContext(ctx, id).Scan(&app.ID, &app.Name)
+	app, err := scanApp(stmt.QueryRowContext(ctx, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.App{}, fmt.Errorf("failed to get app: %w", repository.ErrAppNotFound)
+		}
+
+		return entity.App{}, fmt.Errorf("failed to get app: %w", err)
+	}
+
+	return app, nil
+}
+
+// IsMember checks if user is a member of the app
+func (r *AppRepository) IsMember(ctx context.Context, appID int, userID int64) (_ bool, err error) {
+	const query = "SELECT EXISTS (SELECT 1 FROM app_members WHERE app_id = $1 AND user_id = $2)"
+
+	ctx, span := startSpan(ctx, "AppRepository.IsMember", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var ok bool
+	err = stmt.QueryRowContext(ctx, appID, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check app membership: %w", err)
+	}
+
+	return ok, nil
+}
+
+// AddMember adds user to members of the app and reports whether user wasn't a member before
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+// If user doesn't exist, returns error repository.ErrUserNotFound
+func (r *AppRepository) AddMember(ctx context.Context, appID int, userID int64) (_ bool, err error) {
+	const query = "INSERT INTO app_members (app_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+
+	ctx, span := startSpan(ctx, "AppRepository.AddMember", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, appID, userID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			if pqErr.Constraint == "app_members_app_id_fkey" {
+				return false, fmt.Errorf("failed to add app member: %w", repository.ErrAppNotFound)
+			}
+
+			return false, fmt.Errorf("failed to add app member: %w", repository.ErrUserNotFound)
+		}
+
+		return false, fmt.Errorf("failed to add app member: %w", err)
+	}
+
+	added, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add app member: %w", err)
+	}
+
+	return added > 0, nil
+}
+
+// RemoveMember removes user from members of the app and reports whether user was a member
+func (r *AppRepository) RemoveMember(ctx context.Context, appID int, userID int64) (_ bool, err error) {
+	const query = "DELETE FROM app_members WHERE app_id = $1 AND user_id = $2"
+
+	ctx, span := startSpan(ctx, "AppRepository.RemoveMember", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, appID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove app member: %w", err)
+	}
+
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove app member: %w", err)
+	}
+
+	return removed > 0, nil
+}
+
+// GetMembers returns members of the app ordered from the earliest added
+func (r *AppRepository) GetMembers(ctx context.Context, appID int) (_ []entity.AppMember, err error) {
+	const query = `SELECT m.app_id, m.user_id, u.email, m.created_at
+		FROM app_members m JOIN users u ON u.id = m.user_id
+		WHERE m.app_id = $1
+		ORDER BY m.created_at, m.user_id`
+
+	ctx, span := startSpan(ctx, "AppRepository.GetMembers", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []entity.AppMember
+	for rows.Next() {
+		var member entity.AppMember
+		if err = rows.Scan(&member.AppID, &member.UserID, &member.Email, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan app member: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get app members: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetAppByClientID returns app by its client id
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *AppRepository) GetAppByClientID(ctx context.Context, clientID string) (_ entity.App, err error) {
+	const query = "SELECT " + appColumns + " FROM apps WHERE client_id = $1"
+
+	ctx, span := startSpan(ctx, "AppRepository.GetAppByClientID", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return entity.App{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	app, err := scanApp(stmt.QueryRowContext(ctx, clientID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return entity.App{}, fmt.Errorf("failed to get app: %w", repository.ErrAppNotFound)
@@ -40,3 +201,265 @@ func (r *AppRepository) GetApp(ctx context.Context, id int) (entity.App, error)
 
 	return app, nil
 }
+
+// SaveApp saves new app with hash of its client secret and returns its id, public apps have empty secret hash
+//
+// If app with the same name or client id already exists, returns error repository.ErrAppAlreadyExists
+// If owner doesn't exist, returns error repository.ErrUserNotFound
+func (r *AppRepository) SaveApp(ctx context.Context, app entity.App, secretHash string) (_ int, err error) {
+	const query = `INSERT INTO apps (name, membership_required, client_id, client_secret_hash, redirect_uris, grant_types, scopes, disabled, public, owner_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, NULLIF($10, 0)) RETURNING id`
+
+	ctx, span := startSpan(ctx, "AppRepository.SaveApp", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return -1, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var id int
+	err = stmt.QueryRowContext(
+		ctx,
+		app.Name,
+		app.MembershipRequired,
+		app.ClientID,
+		secretHash,
+		pq.Array(app.RedirectURIs),
+		pq.Array(app.GrantTypes),
+		pq.Array(app.Scopes),
+		app.Disabled,
+		app.Public,
+		app.OwnerID,
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return -1, fmt.Errorf("failed to save app: %w", repository.ErrAppAlreadyExists)
+		}
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			return -1, fmt.Errorf("failed to save app: %w", repository.ErrUserNotFound)
+		}
+
+		return -1, fmt.Errorf("failed to save app: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateApp changes settings of app set in update, other settings are left unchanged.
+// Client secret of app made public is removed
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+// If app with the same name already exists, returns error repository.ErrAppAlreadyExists
+// If new owner doesn't exist, returns error repository.ErrUserNotFound
+func (r *AppRepository) UpdateApp(ctx context.Context, id int, update entity.AppUpdate) (err error) {
+	const query = `UPDATE apps
+		SET name = COALESCE($2, name),
+			membership_required = COALESCE($3, membership_required),
+			redirect_uris = COALESCE($4::text[], redirect_uris),
+			grant_types = COALESCE($5::text[], grant_types),
+			scopes = COALESCE($6::text[], scopes),
+			disabled = COALESCE($7, disabled),
+			public = COALESCE($8, public),
+			client_secret_hash = CASE WHEN $8 THEN NULL ELSE client_secret_hash END,
+			owner_id = COALESCE($9, owner_id),
+			updated_at = now()
+		WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "AppRepository.UpdateApp", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(
+		ctx,
+		id,
+		update.Name,
+		update.MembershipRequired,
+		nullableArray(update.RedirectURIs),
+		nullableArray(update.GrantTypes),
+		nullableArray(update.Scopes),
+		update.Disabled,
+		update.Public,
+		update.OwnerID,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("failed to update app: %w", repository.ErrAppAlreadyExists)
+		}
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			return fmt.Errorf("failed to update app: %w", repository.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("failed to update app: %w", err)
+	}
+
+	return requireAffected(res, "failed to update app", repository.ErrAppNotFound)
+}
+
+// SetAppSecretHash replaces hash of client secret of app
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *AppRepository) SetAppSecretHash(ctx context.Context, id int, secretHash string) (err error) {
+	const query = "UPDATE apps SET client_secret_hash = $2, updated_at = now() WHERE id = $1"
+
+	ctx, span := startSpan(ctx, "AppRepository.SetAppSecretHash", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, secretHash)
+	if err != nil {
+		return fmt.Errorf("failed to set app secret: %w", err)
+	}
+
+	return requireAffected(res, "failed to set app secret", repository.ErrAppNotFound)
+}
+
+// GetAppSecretHash returns hash of client secret of app, empty if app has no secret
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *AppRepository) GetAppSecretHash(ctx context.Context, id int) (_ string, err error) {
+	const query = "SELECT COALESCE(client_secret_hash, '') FROM apps WHERE id = $1"
+
+	ctx, span := startSpan(ctx, "AppRepository.GetAppSecretHash", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var hash string
+	err = stmt.QueryRowContext(ctx, id).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("failed to get app secret: %w", repository.ErrAppNotFound)
+		}
+
+		return "", fmt.Errorf("failed to get app secret: %w", err)
+	}
+
+	return hash, nil
+}
+
+// GetApps returns all apps ordered by id
+func (r *AppRepository) GetApps(ctx context.Context) (_ []entity.App, err error) {
+	const query = "SELECT " + appColumns + " FROM apps ORDER BY id"
+
+	ctx, span := startSpan(ctx, "AppRepository.GetApps", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apps: %w", err)
+	}
+	defer rows.Close()
+
+	var apps []entity.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+
+		apps = append(apps, app)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get apps: %w", err)
+	}
+
+	return apps, nil
+}
+
+// DeleteApp deletes app with its sessions, memberships, role assignments and policies
+//
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *AppRepository) DeleteApp(ctx context.Context, id int) (err error) {
+	const query = "DELETE FROM apps WHERE id = $1"
+
+	ctx, span := startSpan(ctx, "AppRepository.DeleteApp", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete app: %w", err)
+	}
+
+	return requireAffected(res, "failed to delete app", repository.ErrAppNotFound)
+}
+
+// nullableArray returns NULL if values is nil, so COALESCE keeps the stored array
+func nullableArray(values *[]string) any {
+	if values == nil {
+		return nil
+	}
+	if *values == nil {
+		return pq.Array([]string{})
+	}
+
+	return pq.Array(*values)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApp(row rowScanner) (entity.App, error) {
+	var app entity.App
+	err := row.Scan(
+		&app.ID,
+		&app.Name,
+		&app.MembershipRequired,
+		&app.ClientID,
+		pq.Array(&app.RedirectURIs),
+		pq.Array(&app.GrantTypes),
+		pq.Array(&app.Scopes),
+		&app.Disabled,
+		&app.Public,
+		&app.OwnerID,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+	)
+
+	return app, err
+}
+
+// requireAffected returns notFound wrapped with msg if statement affected no rows
+func requireAffected(res sql.Result, msg string, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", msg, notFound)
+	}
+
+	return nil
+}
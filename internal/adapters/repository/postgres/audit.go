@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+)
+
+type AuditRepository struct {
+	db *postgres.Db
+}
+
+func NewAuditRepository(db *postgres.Db) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// SaveEvent appends audit event to the database
+func (r *AuditRepository) SaveEvent(ctx context.Context, event entity.AuditEvent) (_ int64, err error) {
+	const query = `INSERT INTO audit_events (type, success, reason, actor_id, subject_id, app_id, ip, request_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	ctx, span := startSpan(ctx, "AuditRepository.SaveEvent", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return -1, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return -1, fmt.Errorf("failed to marshal details: %w", err)
+	}
+	if event.Details == nil {
+		details = []byte("{}")
+	}
+
+	var id int64
+	err = stmt.QueryRowContext(
+		ctx,
+		event.Type,
+		event.Success,
+		event.Reason,
+		nullInt64(event.ActorID),
+		nullInt64(event.SubjectID),
+		nullInt64(int64(event.AppID)),
+		event.IP,
+		event.RequestID,
+		details,
+		event.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return -1, fmt.Errorf("failed to save audit event: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetEvents returns audit events matching filter ordered from newest to oldest
+func (r *AuditRepository) GetEvents(ctx context.Context, filter entity.AuditEventFilter) (_ []entity.AuditEvent, err error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Type != "" {
+		where("type = $%d", filter.Type)
+	}
+	if filter.ActorID != 0 {
+		where("actor_id = $%d", filter.ActorID)
+	}
+	if filter.SubjectID != 0 {
+		where("subject_id = $%d", filter.SubjectID)
+	}
+	if filter.AppID != 0 {
+		where("app_id = $%d", filter.AppID)
+	}
+	if filter.Success != nil {
+		where("success = $%d", *filter.Success)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+	if filter.BeforeID != 0 {
+		where("id < $%d", filter.BeforeID)
+	}
+
+	query := "SELECT id, type, success, reason, actor_id, subject_id, app_id, ip, request_id, details, created_at FROM audit_events"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	ctx, span := startSpan(ctx, "AuditRepository.GetEvents", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []entity.AuditEvent
+	for rows.Next() {
+		var (
+			event                     entity.AuditEvent
+			actorID, subjectID, appID sql.NullInt64
+			details                   []byte
+		)
+		err = rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.Success,
+			&event.Reason,
+			&actorID,
+			&subjectID,
+			&appID,
+			&event.IP,
+			&event.RequestID,
+			&details,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+
+		event.ActorID = actorID.Int64
+		event.SubjectID = subjectID.Int64
+		event.AppID = int(appID.Int64)
+		if err = json.Unmarshal(details, &event.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal details: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+func nullInt64(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+)
+
+type AuthorizationCodeRepository struct {
+	db *postgres.Db
+}
+
+func NewAuthorizationCodeRepository(db *postgres.Db) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{
+		db: db,
+	}
+}
+
+// SaveCode saves issued authorization code and deletes expired ones
+func (r *AuthorizationCodeRepository) SaveCode(ctx context.Context, code entity.AuthorizationCode) (err error) {
+	const query = `WITH expired AS (DELETE FROM authorization_codes WHERE expires_at < now())
+		INSERT INTO authorization_codes (code_hash, app_id, user_id, redirect_uri, code_challenge, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	ctx, span := startSpan(ctx, "AuthorizationCodeRepository.SaveCode", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		code.CodeHash,
+		code.AppID,
+		code.UserID,
+		code.RedirectURI,
+		code.CodeChallenge,
+		code.CreatedAt,
+		code.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeCode deletes authorization code and returns it, so the code can be exchanged only once,
+// expired codes are returned as well and checking expiration is up to the caller
+//
+// If code doesn't exist or was already consumed, returns error repository.ErrCodeNotFound
+func (r *AuthorizationCodeRepository) ConsumeCode(ctx context.Context, codeHash string) (_ entity.AuthorizationCode, err error) {
+	const query = `DELETE FROM authorization_codes WHERE code_hash = $1
+		RETURNING code_hash, app_id, user_id, redirect_uri, code_challenge, created_at, expires_at`
+
+	ctx, span := startSpan(ctx, "AuthorizationCodeRepository.ConsumeCode", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return entity.AuthorizationCode{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var code entity.AuthorizationCode
+	err = stmt.QueryRowContext(ctx, codeHash).Scan(
+		&code.CodeHash,
+		&code.AppID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.CodeChallenge,
+		&code.CreatedAt,
+		&code.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.AuthorizationCode{}, fmt.Errorf("failed to consume authorization code: %w", repository.ErrCodeNotFound)
+		}
+
+		return entity.AuthorizationCode{}, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"github.com/lib/pq"
+)
+
+const policyColumns = "id, name, version, description, action, COALESCE(app_id, 0), effect, expression, dry_run, enabled, COALESCE(created_by, 0), created_at"
+
+type PolicyRepository struct {
+	db *postgres.Db
+}
+
+func NewPolicyRepository(db *postgres.Db) *PolicyRepository {
+	return &PolicyRepository{
+		db: db,
+	}
+}
+
+// SavePolicy saves policy as its next version and returns saved policy
+//
+// If another version of policy was saved concurrently, returns error repository.ErrPolicyConflict
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *PolicyRepository) SavePolicy(ctx context.Context, policy entity.Policy) (_ entity.Policy, err error) {
+	const query = `INSERT INTO policies (name, version, description, action, app_id, effect, expression, dry_run, enabled, created_by)
+		VALUES ($1, (SELECT COALESCE(MAX(version), 0) + 1 FROM policies WHERE name = $1), $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9)
+		RETURNING id, version, created_at`
+
+	ctx, span := startSpan(ctx, "PolicyRepository.SavePolicy", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return entity.Policy{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(
+		ctx,
+		policy.Name,
+		policy.Description,
+		policy.Action,
+		policy.AppID,
+		policy.Effect,
+		policy.Expression,
+		policy.DryRun,
+		policy.Enabled,
+		nullInt64(policy.CreatedBy),
+	).Scan(&policy.ID, &policy.Version, &policy.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return entity.Policy{}, fmt.Errorf("failed to save policy: %w", repository.ErrPolicyConflict)
+		}
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "policies_app_id_fkey" {
+			return entity.Policy{}, fmt.Errorf("failed to save policy: %w", repository.ErrAppNotFound)
+		}
+
+		return entity.Policy{}, fmt.Errorf("failed to save policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// GetEffectivePolicies returns the latest enabled versions of policies of action applying to the app
+func (r *PolicyRepository) GetEffectivePolicies(ctx context.Context, action string, appID int) (_ []entity.Policy, err error) {
+	const query = `SELECT ` + policyColumns + ` FROM (
+			SELECT DISTINCT ON (name) * FROM policies ORDER BY name, version DESC
+		) p
+		WHERE enabled AND action = $1 AND (app_id IS NULL OR app_id = $2)
+		ORDER BY name`
+
+	ctx, span := startSpan(ctx, "PolicyRepository.GetEffectivePolicies", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.queryPolicies(ctx, query, action, appID)
+}
+
+// GetPolicies returns the latest versions of all policies ordered by name,
+// or all versions of policy from newest to oldest if name isn't empty
+func (r *PolicyRepository) GetPolicies(ctx context.Context, name string) (_ []entity.Policy, err error) {
+	const (
+		latestQuery   = `SELECT DISTINCT ON (name) ` + policyColumns + ` FROM policies ORDER BY name, version DESC`
+		versionsQuery = `SELECT ` + policyColumns + ` FROM policies WHERE name = $1 ORDER BY version DESC`
+	)
+
+	query, args := latestQuery, []any(nil)
+	if name != "" {
+		query, args = versionsQuery, []any{name}
+	}
+
+	ctx, span := startSpan(ctx, "PolicyRepository.GetPolicies", query)
+	defer func() { tracing.End(span, err) }()
+
+	return r.queryPolicies(ctx, query, args...)
+}
+
+func (r *PolicyRepository) queryPolicies(ctx context.Context, query string, args ...any) ([]entity.Policy, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []entity.Policy
+	for rows.Next() {
+		var policy entity.Policy
+		err = rows.Scan(
+			&policy.ID,
+			&policy.Name,
+			&policy.Version,
+			&policy.Description,
+			&policy.Action,
+			&policy.AppID,
+			&policy.Effect,
+			&policy.Expression,
+			&policy.DryRun,
+			&policy.Enabled,
+			&policy.CreatedBy,
+			&policy.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+
+	return policies, nil
+}
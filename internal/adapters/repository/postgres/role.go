@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"github.com/lib/pq"
+)
+
+type RoleRepository struct {
+	db *postgres.Db
+}
+
+func NewRoleRepository(db *postgres.Db) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+// GetUserRoles returns roles of user with their permissions ordered by name
+//
+// If appID isn't zero, returns only roles effective in the app, i.e. global ones and assigned in the app
+func (r *RoleRepository) GetUserRoles(ctx context.Context, userID int64, appID int) (_ []entity.Role, err error) {
+	const query = `SELECT r.id, r.name, r.description, COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}'), COALESCE(ur.app_id, 0)
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1 AND ($2 = 0 OR ur.app_id IS NULL OR ur.app_id = $2)
+		GROUP BY r.id, ur.app_id
+		ORDER BY r.name, ur.app_id NULLS FIRST`
+
+	ctx, span := startSpan(ctx, "RoleRepository.GetUserRoles", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []entity.Role
+	for rows.Next() {
+		var role entity.Role
+		err = rows.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions), &role.AppID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// HasPermission checks if any role of user effective in the app grants permission,
+// only global roles are checked if appID is zero
+func (r *RoleRepository) HasPermission(ctx context.Context, userID int64, permission string, appID int) (_ bool, err error) {
+	const query = `SELECT EXISTS (
+		SELECT 1
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1 AND p.name = $2 AND (ur.app_id IS NULL OR ur.app_id = $3)
+	)`
+
+	ctx, span := startSpan(ctx, "RoleRepository.HasPermission", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var ok bool
+	err = stmt.QueryRowContext(ctx, userID, permission, appID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return ok, nil
+}
+
+// GrantRole assigns role to user globally if appID is zero or in the app otherwise,
+// returns false if user already has the role
+//
+// If role doesn't exist, returns error repository.ErrRoleNotFound
+// If user doesn't exist, returns error repository.ErrUserNotFound
+// If app doesn't exist, returns error repository.ErrAppNotFound
+func (r *RoleRepository) GrantRole(ctx context.Context, userID int64, role string, appID int) (_ bool, err error) {
+	const query = `INSERT INTO user_roles (user_id, role_id, app_id)
+		SELECT $1, id, NULLIF($3, 0) FROM roles WHERE name = $2
+		ON CONFLICT DO NOTHING`
+
+	ctx, span := startSpan(ctx, "RoleRepository.GrantRole", query)
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = roleExists(ctx, tx, role); err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, query, userID, role, appID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			if pqErr.Constraint == "user_roles_app_id_fkey" {
+				return false, fmt.Errorf("failed to grant role: %w", repository.ErrAppNotFound)
+			}
+
+			return false, fmt.Errorf("failed to grant role: %w", repository.ErrUserNotFound)
+		}
+
+		return false, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	granted, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return granted > 0, nil
+}
+
+// RevokeRole removes role assigned to user globally if appID is zero or in the app otherwise,
+// returns false if user doesn't have the role
+//
+// If role doesn't exist, returns error repository.ErrRoleNotFound
+// If revocation would leave no one with admin permission granted globally, returns error repository.ErrLastAdmin
+func (r *RoleRepository) RevokeRole(ctx context.Context, userID int64, role string, appID int) (_ bool, err error) {
+	const (
+		// lockAdmins serializes revocations so concurrent ones can't remove all admins
+		lockAdmins = `SELECT ur.user_id
+			FROM user_roles ur
+			JOIN role_permissions rp ON rp.role_id = ur.role_id
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE p.name = $1 AND ur.app_id IS NULL
+			FOR UPDATE OF ur`
+		query = `DELETE FROM user_roles ur
+			USING roles r
+			WHERE r.id = ur.role_id AND ur.user_id = $1 AND r.name = $2 AND COALESCE(ur.app_id, 0) = $3`
+		countAdmins = `SELECT COUNT(DISTINCT ur.user_id)
+			FROM user_roles ur
+			JOIN role_permissions rp ON rp.role_id = ur.role_id
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE p.name = $1 AND ur.app_id IS NULL`
+	)
+
+	ctx, span := startSpan(ctx, "RoleRepository.RevokeRole", query)
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = roleExists(ctx, tx, role); err != nil {
+		return false, err
+	}
+
+	if _, err = tx.ExecContext(ctx, lockAdmins, entity.PermissionAdmin); err != nil {
+		return false, fmt.Errorf("failed to lock admins: %w", err)
+	}
+
+	var adminsBefore int
+	if err = tx.QueryRowContext(ctx, countAdmins, entity.PermissionAdmin).Scan(&adminsBefore); err != nil {
+		return false, fmt.Errorf("failed to count admins: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, userID, role, appID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	revoked, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	var adminsAfter int
+	if err = tx.QueryRowContext(ctx, countAdmins, entity.PermissionAdmin).Scan(&adminsAfter); err != nil {
+		return false, fmt.Errorf("failed to count admins: %w", err)
+	}
+
+	if adminsBefore > 0 && adminsAfter == 0 {
+		return false, fmt.Errorf("failed to revoke role: %w", repository.ErrLastAdmin)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return revoked > 0, nil
+}
+
+func roleExists(ctx context.Context, tx *sql.Tx, role string) error {
+	const query = "SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)"
+
+	var ok bool
+	if err := tx.QueryRowContext(ctx, query, role).Scan(&ok); err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("failed to get role: %w", repository.ErrRoleNotFound)
+	}
+
+	return nil
+}
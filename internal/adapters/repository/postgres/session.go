@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+)
+
+type SessionRepository struct {
+	db *postgres.Db
+}
+
+func NewSessionRepository(db *postgres.Db) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// SaveSession saves session to the database
+func (r *SessionRepository) SaveSession(ctx context.Context, session entity.Session) (_ int64, err error) {
+	const query = `INSERT INTO sessions (user_id, app_id, ip, user_agent, refresh_token_hash, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	ctx, span := startSpan(ctx, "SessionRepository.SaveSession", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return -1, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(
+		ctx,
+		session.UserID,
+		session.AppID,
+		session.IP,
+		session.UserAgent,
+		session.RefreshTokenHash,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		return -1, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUserSessions returns active sessions of user ordered from newest to oldest
+func (r *SessionRepository) GetUserSessions(ctx context.Context, userID int64) (_ []entity.Session, err error) {
+	const query = `SELECT id, user_id, app_id, ip, user_agent, refresh_token_hash, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+		ORDER BY created_at DESC`
+
+	ctx, span := startSpan(ctx, "SessionRepository.GetUserSessions", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []entity.Session
+	for rows.Next() {
+		var session entity.Session
+		err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.AppID,
+			&session.IP,
+			&session.UserAgent,
+			&session.RefreshTokenHash,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// IsSessionActive checks if session exists and is neither revoked nor expired
+func (r *SessionRepository) IsSessionActive(ctx context.Context, sessionID int64) (_ bool, err error) {
+	const query = "SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL AND expires_at > now())"
+
+	ctx, span := startSpan(ctx, "SessionRepository.IsSessionActive", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var ok bool
+	err = stmt.QueryRowContext(ctx, sessionID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return ok, nil
+}
+
+// RevokeSession revokes active session of user
+//
+// If session doesn't exist, belongs to another user or is already revoked, returns error repository.ErrSessionNotFound
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID int64, sessionID int64) (err error) {
+	const query = "UPDATE sessions SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL"
+
+	ctx, span := startSpan(ctx, "SessionRepository.RevokeSession", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("failed to revoke session: %w", repository.ErrSessionNotFound)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes all active sessions of user and returns their count
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID int64) (_ int64, err error) {
+	const query = "UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL"
+
+	ctx, span := startSpan(ctx, "SessionRepository.RevokeUserSessions", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return -1, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, userID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return -1, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return affected, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
 	"github.com/4aykovski/grpc_auth_sso/internal/entity"
 	"github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
 	"github.com/lib/pq"
 )
 
@@ -23,9 +24,14 @@ func NewUserRepository(db *postgres.Db) *UserRepository {
 }
 
 // SaveUser saves user to the database
-func (r *UserRepository) SaveUser(ctx context.Context, user entity.User) (int64, error) {
+func (r *UserRepository) SaveUser(ctx context.Context, user entity.User) (_ int64, err error) {
 
-	stmt, err := r.db.Prepare("INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id")
+	const query = "INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id"
+
+	ctx, span := startSpan(ctx, "UserRepository.SaveUser", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return -1, fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -46,8 +52,13 @@ func (r *UserRepository) SaveUser(ctx context.Context, user entity.User) (int64,
 }
 
 // GetUser returns user by email
-func (r *UserRepository) GetUser(ctx context.Context, email string) (entity.User, error) {
-	stmt, err := r.db.Prepare("SELECT * FROM users WHERE email = $1")
+func (r *UserRepository) GetUser(ctx context.Context, email string) (_ entity.User, err error) {
+	const query = "SELECT * FROM users WHERE email = $1"
+
+	ctx, span := startSpan(ctx, "UserRepository.GetUser", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return entity.User{}, fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -65,3 +76,29 @@ func (r *UserRepository) GetUser(ctx context.Context, email string) (entity.User
 
 	return user, nil
 }
+
+// GetUserByID returns user by id
+func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (_ entity.User, err error) {
+	const query = "SELECT id, email, password FROM users WHERE id = $1"
+
+	ctx, span := startSpan(ctx, "UserRepository.GetUserByID", query)
+	defer func() { tracing.End(span, err) }()
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var user entity.User
+	err = stmt.QueryRowContext(ctx, id).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.User{}, fmt.Errorf("failed to get user: %w", repository.ErrUserNotFound)
+		}
+
+		return entity.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
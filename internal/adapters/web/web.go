@@ -0,0 +1,181 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_protos/gen/go/sso/ssov1connect"
+	"github.com/rs/cors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	headerRequestID      = "x-request-id"
+	headerAuthorization  = "authorization"
+	headerAcceptLanguage = "accept-language"
+
+	// headers trusted by gRPC server from in-process clients
+	headerForwardedFor       = "x-forwarded-for"
+	headerForwardedUserAgent = "x-forwarded-user-agent"
+)
+
+type CORSOptions struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// New returns HTTP handler serving Auth service over Connect and gRPC-Web protocols to browsers
+//
+// Native gRPC isn't served as the handler runs over HTTP/1.1, gRPC clients use gRPC listeners instead.
+// Calls are proxied to gRPC server over conn, so they pass the same handlers and interceptors
+// as native gRPC requests
+func New(conn *grpc.ClientConn, corsOpts CORSOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(ssov1connect.NewAuthHandler(&authHandler{client: ssov1.NewAuthClient(conn)}))
+
+	return cors.New(cors.Options{
+		AllowedOrigins:   corsOpts.AllowedOrigins,
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin", "X-Request-Id"},
+		AllowCredentials: corsOpts.AllowCredentials,
+		MaxAge:           int(corsOpts.MaxAge.Seconds()),
+	}).Handler(mux)
+}
+
+type authHandler struct {
+	client ssov1.AuthClient
+}
+
+func (h *authHandler) Register(
+	ctx context.Context,
+	req *connect.Request[ssov1.RegisterRequest],
+) (*connect.Response[ssov1.RegisterResponse], error) {
+	return call(ctx, req, h.client.Register)
+}
+
+func (h *authHandler) Login(
+	ctx context.Context,
+	req *connect.Request[ssov1.LoginRequest],
+) (*connect.Response[ssov1.LoginResponse], error) {
+	return call(ctx, req, h.client.Login)
+}
+
+func (h *authHandler) IsAdmin(
+	ctx context.Context,
+	req *connect.Request[ssov1.IsAdminRequest],
+) (*connect.Response[ssov1.IsAdminResponse], error) {
+	return call(ctx, req, h.client.IsAdmin)
+}
+
+func (h *authHandler) ListSessions(
+	ctx context.Context,
+	req *connect.Request[ssov1.ListSessionsRequest],
+) (*connect.Response[ssov1.ListSessionsResponse], error) {
+	return call(ctx, req, h.client.ListSessions)
+}
+
+func (h *authHandler) RevokeSession(
+	ctx context.Context,
+	req *connect.Request[ssov1.RevokeSessionRequest],
+) (*connect.Response[ssov1.RevokeSessionResponse], error) {
+	return call(ctx, req, h.client.RevokeSession)
+}
+
+func (h *authHandler) RevokeAllSessions(
+	ctx context.Context,
+	req *connect.Request[ssov1.RevokeAllSessionsRequest],
+) (*connect.Response[ssov1.RevokeAllSessionsResponse], error) {
+	return call(ctx, req, h.client.RevokeAllSessions)
+}
+
+func (h *authHandler) CheckPermission(
+	ctx context.Context,
+	req *connect.Request[ssov1.CheckPermissionRequest],
+) (*connect.Response[ssov1.CheckPermissionResponse], error) {
+	return call(ctx, req, h.client.CheckPermission)
+}
+
+func (h *authHandler) ListUserRoles(
+	ctx context.Context,
+	req *connect.Request[ssov1.ListUserRolesRequest],
+) (*connect.Response[ssov1.ListUserRolesResponse], error) {
+	return call(ctx, req, h.client.ListUserRoles)
+}
+
+func (h *authHandler) Authorize(
+	ctx context.Context,
+	req *connect.Request[ssov1.AuthorizeRequest],
+) (*connect.Response[ssov1.AuthorizeResponse], error) {
+	return call(ctx, req, h.client.Authorize)
+}
+
+// call invokes gRPC method forwarding client address, user agent, request id, language and credentials,
+// and converts its response metadata and status to Connect ones
+func call[Req, Res any](
+	ctx context.Context,
+	req *connect.Request[Req],
+	method func(context.Context, *Req, ...grpc.CallOption) (*Res, error),
+) (*connect.Response[Res], error) {
+	md := metadata.MD{}
+	if host, _, err := net.SplitHostPort(req.Peer().Addr); err == nil {
+		md.Set(headerForwardedFor, host)
+	}
+	if userAgent := req.Header().Get("User-Agent"); userAgent != "" {
+		md.Set(headerForwardedUserAgent, userAgent)
+	}
+	for _, key := range []string{headerRequestID, headerAuthorization, headerAcceptLanguage} {
+		if value := req.Header().Get(key); value != "" {
+			md.Set(key, value)
+		}
+	}
+
+	var header metadata.MD
+	res, err := method(metadata.NewOutgoingContext(ctx, md), req.Msg, grpc.Header(&header))
+	if err != nil {
+		connectErr := toConnectError(err)
+		copyHeader(connectErr.Meta(), header)
+
+		return nil, connectErr
+	}
+
+	resp := connect.NewResponse(res)
+	copyHeader(resp.Header(), header)
+
+	return resp, nil
+}
+
+func toConnectError(err error) *connect.Error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Proto().GetDetails() {
+		msg, err := detail.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+
+		if d, err := connect.NewErrorDetail(msg); err == nil {
+			connectErr.AddDetail(d)
+		}
+	}
+
+	return connectErr
+}
+
+func copyHeader(dst http.Header, md metadata.MD) {
+	if values := md.Get(headerRequestID); len(values) > 0 {
+		dst.Set(headerRequestID, values[0])
+	}
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_protos/gen/go/sso/ssov1connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const allowedOrigin = "http://localhost:3000"
+
+type authServer struct {
+	ssov1.UnimplementedAuthServer
+}
+
+func (authServer) Register(context.Context, *ssov1.RegisterRequest) (*ssov1.RegisterResponse, error) {
+	return &ssov1.RegisterResponse{UserId: 1}, nil
+}
+
+// newHandler returns web handler proxying calls to in-process gRPC server
+func newHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	l := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	ssov1.RegisterAuthServer(server, authServer{})
+	go func() { _ = server.Serve(l) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///web",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return New(conn, CORSOptions{
+		AllowedOrigins: []string{allowedOrigin},
+		MaxAge:         10 * time.Minute,
+	})
+}
+
+func TestNew_Preflight(t *testing.T) {
+	handler := newHandler(t)
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "allowed origin", origin: allowedOrigin, allowed: true},
+		{name: "unknown origin", origin: "http://evil.example.com", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, ssov1connect.AuthRegisterProcedure, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "content-type,authorization")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if !tt.allowed {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+				return
+			}
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, http.MethodPost, rec.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "content-type,authorization", strings.ToLower(rec.Header().Get("Access-Control-Allow-Headers")))
+			assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		})
+	}
+}
+
+func TestNew_Call(t *testing.T) {
+	handler := newHandler(t)
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "allowed origin", origin: allowedOrigin, allowed: true},
+		{name: "unknown origin", origin: "http://evil.example.com", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, ssov1connect.AuthRegisterProcedure,
+				strings.NewReader(`{"email":"user@example.com","password":"password"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Origin", tt.origin)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			// CORS is enforced by browsers, the call itself is served regardless of origin
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.JSONEq(t, `{"userId":"1"}`, rec.Body.String())
+
+			if !tt.allowed {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+				return
+			}
+
+			assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Grpc-Status-Details-Bin")
+		})
+	}
+}
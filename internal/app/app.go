@@ -1,52 +1,326 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"log/slog"
+	"net"
+	"strconv"
 	"time"
 
+	auditAdapter "github.com/4aykovski/grpc_auth_sso/internal/adapters/audit"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/debug"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/gateway"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/interceptor"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/oauth"
 	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository/postgres"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/web"
 	grpcapp "github.com/4aykovski/grpc_auth_sso/internal/app/grpc"
+	httpapp "github.com/4aykovski/grpc_auth_sso/internal/app/http"
+	metricsapp "github.com/4aykovski/grpc_auth_sso/internal/app/metrics"
+	"github.com/4aykovski/grpc_auth_sso/internal/config"
+	"github.com/4aykovski/grpc_auth_sso/internal/metrics"
+	appservice "github.com/4aykovski/grpc_auth_sso/internal/service/app"
+	"github.com/4aykovski/grpc_auth_sso/internal/service/audit"
 	"github.com/4aykovski/grpc_auth_sso/internal/service/auth"
+	"github.com/4aykovski/grpc_auth_sso/internal/service/policy"
 	pgDatabase "github.com/4aykovski/grpc_auth_sso/pkg/database/postgres"
 	"github.com/4aykovski/grpc_auth_sso/pkg/hasher"
 	"github.com/4aykovski/grpc_auth_sso/pkg/manager/secret"
 	"github.com/4aykovski/grpc_auth_sso/pkg/manager/token"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tlsreload"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
 )
 
+const tracingShutdownTimeout = 5 * time.Second
+
+// gatewayListener is a name of in-process gRPC listener HTTP/JSON gateway calls
+const gatewayListener = "gateway"
+
+// webListener is a name of in-process gRPC listener gRPC-Web and Connect handlers call
+const webListener = "web"
+
 type App struct {
 	GRPCApp *grpcapp.App
+	// MetricsApp is nil if metrics listener is disabled
+	MetricsApp *metricsapp.App
+	// GatewayApp is nil if HTTP/JSON gateway is disabled
+	GatewayApp *httpapp.App
+	// WebApp is nil if gRPC-Web and Connect listener is disabled
+	WebApp *httpapp.App
+	// OAuthApp is nil if OAuth authorization server is disabled
+	OAuthApp *httpapp.App
+	// DebugApp serves pprof, it is nil if debugging tools are disabled
+	DebugApp *httpapp.App
+
+	log     *slog.Logger
+	tracing *tracing.Provider
+	// auditFile is nil if audit events aren't written to a file
+	auditFile *auditAdapter.FileSink
 }
 
 func New(
 	log *slog.Logger,
-	dSNTemplate string,
-	port int,
-	accessTokenTTL time.Duration,
-	refreshTokenTTL time.Duration,
+	cfg *config.Config,
+	logLevel *slog.LevelVar,
 ) (*App, error) {
 
-	pgdb, err := pgDatabase.New(dSNTemplate)
+	var tracingProvider *tracing.Provider
+	if cfg.Tracing.Enabled {
+		var err error
+		tracingProvider, err = tracing.New(context.Background(), tracing.Options{
+			Endpoint:    cfg.Tracing.Endpoint,
+			Insecure:    cfg.Tracing.Insecure,
+			ServiceName: cfg.Tracing.ServiceName,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tracing.SetPropagator()
+	}
+
+	pgdb, err := pgDatabase.New(cfg.Postgres.DSNTemplate)
 	if err != nil {
 		return nil, err
 	}
 
-	adminRepo := postgres.NewAdminRepository(pgdb)
+	appMetrics := metrics.New()
+	appMetrics.RegisterDB(pgdb.DB, cfg.Postgres.Database)
+
+	roleRepo := postgres.NewRoleRepository(pgdb)
 	userRepo := postgres.NewUserRepository(pgdb)
 	appRepo := postgres.NewAppRepository(pgdb)
+	sessionRepo := postgres.NewSessionRepository(pgdb)
+	auditRepo := postgres.NewAuditRepository(pgdb)
+	codeRepo := postgres.NewAuthorizationCodeRepository(pgdb)
+	policyRepo := postgres.NewPolicyRepository(pgdb)
+
+	tokenManager, err := token.Load(cfg.TokenSigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token signing key, generate one with task token-key: %w", err)
+	}
+	secretManager := secret.New(appRepo)
+	bcrypt := metrics.NewHasher(&hasher.BCrypt{}, appMetrics)
+
+	var auditService *audit.Service
+	var auditFile *auditAdapter.FileSink
+	if cfg.Audit.File != "" {
+		auditFile, err = auditAdapter.NewFileSink(cfg.Audit.File)
+		if err != nil {
+			return nil, err
+		}
+
+		auditService = audit.New(log, auditRepo, appMetrics, auditFile)
+	} else {
+		auditService = audit.New(log, auditRepo, appMetrics)
+	}
+
+	policyService, err := policy.New(log, policyRepo, userRepo, roleRepo, appRepo, auditService)
+	if err != nil {
+		return nil, err
+	}
+
+	authService := auth.New(log, userRepo, appRepo, roleRepo, sessionRepo, codeRepo, auditService, policyService, tokenManager, secretManager, bcrypt, cfg.AccessTokenTtl, cfg.RefreshTokenTtl, cfg.OAuth.CodeTTL, cfg.TokenRoleClaims)
+	appService := appservice.New(log, appRepo, secretManager, auditService)
+
+	methodTimeouts := make(map[string]interceptor.Timeouts, len(cfg.GRPC.MethodTimeouts))
+	for method, timeout := range cfg.GRPC.MethodTimeouts {
+		methodTimeouts[method] = interceptor.Timeouts{
+			Default: timeout.Timeout,
+			Max:     timeout.MaxTimeout,
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.GRPC.TLS.Enabled {
+		certs, err := tlsreload.New(cfg.GRPC.TLS.CertFile, cfg.GRPC.TLS.KeyFile, cfg.GRPC.TLS.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig = certs.TLSConfig(cfg.GRPC.TLS.RequireClientCert)
+	}
+
+	listeners := []grpcapp.Listener{{
+		Name:     "main",
+		Network:  "tcp",
+		Address:  net.JoinHostPort(cfg.GRPC.Host, strconv.Itoa(cfg.GRPC.Port)),
+		TLS:      true,
+		Services: cfg.GRPC.Services,
+	}}
+	for i, l := range cfg.GRPC.Listeners {
+		if l.Name == "" {
+			l.Name = fmt.Sprintf("listener-%d", i+1)
+		}
+		if l.Network == "" {
+			l.Network = "tcp"
+		}
+		if l.Network != "tcp" && l.Network != "unix" {
+			return nil, fmt.Errorf("unsupported network %q of gRPC listener %q", l.Network, l.Name)
+		}
+		if l.TLS && tlsConfig == nil {
+			return nil, fmt.Errorf("gRPC listener %q requires TLS but grpc.tls is disabled", l.Name)
+		}
 
-	tokenManager := &token.Manager{}
-	secretManager := &secret.Manager{}
-	bcrypt := &hasher.BCrypt{}
+		listeners = append(listeners, grpcapp.Listener{
+			Name:     l.Name,
+			Network:  l.Network,
+			Address:  l.Address,
+			TLS:      l.TLS,
+			Services: l.Services,
+		})
+	}
+
+	if cfg.Gateway.Enabled {
+		listeners = append(listeners, grpcapp.Listener{
+			Name:     gatewayListener,
+			Network:  grpcapp.NetworkInProcess,
+			Services: cfg.Gateway.Services,
+		})
+	}
 
-	authService := auth.New(log, userRepo, appRepo, adminRepo, tokenManager, secretManager, bcrypt, accessTokenTTL, refreshTokenTTL)
+	if cfg.Web.Enabled {
+		listeners = append(listeners, grpcapp.Listener{
+			Name:     webListener,
+			Network:  grpcapp.NetworkInProcess,
+			Services: []string{grpcapp.ServiceAuth},
+		})
+	}
 
-	gRPCApp := grpcapp.New(
+	gRPCApp, err := grpcapp.New(
 		log,
 		authService,
-		port,
+		auditService,
+		authService,
+		authService,
+		policyService,
+		appService,
+		logLevel,
+		appMetrics,
+		pgdb,
+		grpcapp.HealthOptions{
+			CheckInterval: cfg.Health.CheckInterval,
+			CheckTimeout:  cfg.Health.CheckTimeout,
+			ShutdownDelay: cfg.Health.ShutdownDelay,
+		},
+		interceptor.Timeouts{
+			Default: cfg.GRPC.Timeout,
+			Max:     cfg.GRPC.MaxTimeout,
+		},
+		methodTimeouts,
+		tlsConfig,
+		cfg.GRPC.TLS.AdminClients,
+		cfg.Debug.Enabled,
+		listeners,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC app: %w", err)
+	}
+
+	var metricsApp *metricsapp.App
+	if cfg.Metrics.Enabled {
+		metricsApp = metricsapp.New(
+			log,
+			appMetrics.Registry,
+			cfg.Metrics.Host,
+			cfg.Metrics.Port,
+			cfg.Metrics.Path,
+		)
+	}
+
+	var gatewayApp *httpapp.App
+	if cfg.Gateway.Enabled {
+		conn, err := gRPCApp.Conn(gatewayListener)
+		if err != nil {
+			return nil, err
+		}
+
+		handler, err := gateway.New(context.Background(), conn)
+		if err != nil {
+			return nil, err
+		}
+
+		gatewayApp = httpapp.New(log, "gateway", handler, cfg.Gateway.Host, cfg.Gateway.Port)
+	}
+
+	var webApp *httpapp.App
+	if cfg.Web.Enabled {
+		conn, err := gRPCApp.Conn(webListener)
+		if err != nil {
+			return nil, err
+		}
+
+		handler := web.New(conn, web.CORSOptions{
+			AllowedOrigins:   cfg.Web.CORS.AllowedOrigins,
+			AllowCredentials: cfg.Web.CORS.AllowCredentials,
+			MaxAge:           cfg.Web.CORS.MaxAge,
+		})
+
+		webApp = httpapp.New(log, "web", handler, cfg.Web.Host, cfg.Web.Port)
+	}
+
+	var oauthApp *httpapp.App
+	if cfg.OAuth.Enabled {
+		oauthApp = httpapp.New(log, "oauth", oauth.New(log, authService, tokenManager.JWKS()), cfg.OAuth.Host, cfg.OAuth.Port)
+	}
+
+	var debugApp *httpapp.App
+	if cfg.Debug.Enabled {
+		debugApp = httpapp.New(log, "debug", debug.NewHandler(), cfg.Debug.Host, cfg.Debug.Port)
+	}
 
 	return &App{
-		GRPCApp: gRPCApp,
+		GRPCApp:    gRPCApp,
+		MetricsApp: metricsApp,
+		GatewayApp: gatewayApp,
+		WebApp:     webApp,
+		OAuthApp:   oauthApp,
+		DebugApp:   debugApp,
+		log:        log,
+		tracing:    tracingProvider,
+		auditFile:  auditFile,
 	}, nil
 }
+
+// Stop gracefully stops all servers of the application, closes audit file and flushes buffered spans
+func (a *App) Stop() {
+	if a.GatewayApp != nil {
+		a.GatewayApp.Stop()
+	}
+
+	if a.WebApp != nil {
+		a.WebApp.Stop()
+	}
+
+	if a.OAuthApp != nil {
+		a.OAuthApp.Stop()
+	}
+
+	if a.DebugApp != nil {
+		a.DebugApp.Stop()
+	}
+
+	a.GRPCApp.Stop()
+
+	if a.auditFile != nil {
+		if err := a.auditFile.Close(); err != nil {
+			a.log.Error("failed to close audit file", slog.String("error", err.Error()))
+		}
+	}
+
+	if a.MetricsApp != nil {
+		a.MetricsApp.Stop()
+	}
+
+	if a.tracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := a.tracing.Shutdown(ctx); err != nil {
+			a.log.Error("failed to shutdown tracing", slog.String("error", err.Error()))
+		}
+	}
+}
@@ -1,60 +1,300 @@
 package grpcapp
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net"
+	"os"
+	"slices"
+	"time"
 
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	adminGRPC "github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/admin"
 	authGRPC "github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/auth"
+	healthGRPC "github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/health"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/interceptor"
+	"github.com/4aykovski/grpc_auth_sso/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
 )
 
+// services which can be exposed on a listener
+const (
+	ServiceAuth   = "auth"
+	ServiceAdmin  = "admin"
+	ServiceHealth = "health"
+	// ServiceDebug is gRPC reflection and channelz, it is served only in debug mode
+	// and only on listeners listing it explicitly
+	ServiceDebug = "debug"
+)
+
+// allServices are services exposed on listeners without explicit services
+var allServices = []string{ServiceAuth, ServiceAdmin, ServiceHealth}
+
+// NetworkInProcess is a network of listener served in memory, it is reachable only through Conn
+const NetworkInProcess = "inprocess"
+
+const inProcessBufSize = 1024 * 1024
+
 type App struct {
-	log        *slog.Logger
+	log           *slog.Logger
+	servers       []server
+	health        *healthGRPC.Checker
+	shutdownDelay time.Duration
+}
+
+// Listener describes address gRPC server listens on and services it exposes there
+type Listener struct {
+	Name string
+	// Network is "tcp", "unix" or NetworkInProcess
+	Network string
+	// Address is host:port for tcp or socket path for unix network
+	Address string
+	// TLS enables TLS on the listener if server TLS is configured
+	TLS bool
+	// Services are exposed services, all except ServiceDebug if empty
+	Services []string
+}
+
+type server struct {
+	listener   Listener
 	gRPCServer *grpc.Server
-	port       int
+	// inProcess is a listener of NetworkInProcess network
+	inProcess *bufconn.Listener
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type HealthOptions struct {
+	CheckInterval time.Duration
+	CheckTimeout  time.Duration
+	// ShutdownDelay is a time between reporting NOT_SERVING and stopping the server,
+	// so load balancers stop sending new requests
+	ShutdownDelay time.Duration
+}
+
+// PolicyService evaluates and manages authorization policies
+type PolicyService interface {
+	authGRPC.PolicyService
+	adminGRPC.PolicyService
 }
 
 func New(
 	log *slog.Logger,
 	authService authGRPC.AuthService,
-	port int,
-) *App {
+	auditService adminGRPC.AuditService,
+	accessService adminGRPC.AccessService,
+	authenticator interceptor.Authenticator,
+	policyService PolicyService,
+	appService adminGRPC.AppService,
+	logLevel adminGRPC.LogLevel,
+	metrics *metrics.Metrics,
+	db pinger,
+	healthOpts HealthOptions,
+	timeouts interceptor.Timeouts,
+	methodTimeouts map[string]interceptor.Timeouts,
+	tlsConfig *tls.Config,
+	adminClients []string,
+	debug bool,
+	listeners []Listener,
+) (*App, error) {
 
-	gRPCServer := grpc.NewServer()
-
-	authGRPC.Register(gRPCServer, log, authService)
+	interceptors := []grpc.UnaryServerInterceptor{
+		interceptor.RequestMeta(),
+		interceptor.Locale(),
+		interceptor.Deadline(timeouts, methodTimeouts),
+		interceptor.Logging(log),
+		interceptor.Metrics(metrics),
+		interceptor.Recovery(log, metrics),
+	}
+	if len(adminClients) > 0 {
+		interceptors = append(interceptors, interceptor.AllowedClients(
+			[]string{ssov1.Admin_ServiceDesc.ServiceName},
+			adminClients,
+		))
+	}
+	interceptors = append(interceptors, interceptor.Auth(log, authenticator, policies))
 
-	return &App{
-		log:        log,
-		gRPCServer: gRPCServer,
-		port:       port,
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		interceptor.StreamRequestMeta(),
+		interceptor.StreamLogging(log),
+		interceptor.StreamMetrics(metrics),
+		interceptor.StreamRecovery(log, metrics),
+		interceptor.StreamAuth(log, authenticator, policies),
 	}
-}
 
-func (a *App) MustRun() {
-	if err := a.Run(); err != nil {
-		panic("failed to run gRPC server: " + err.Error())
+	health := healthGRPC.New(
+		log,
+		db,
+		healthOpts.CheckInterval,
+		healthOpts.CheckTimeout,
+		ssov1.Auth_ServiceDesc.ServiceName,
+		ssov1.Admin_ServiceDesc.ServiceName,
+	)
+
+	servers := make([]server, 0, len(listeners))
+	for _, l := range listeners {
+		if len(l.Services) == 0 {
+			l.Services = allServices
+		}
+		for _, service := range l.Services {
+			if !slices.Contains(allServices, service) && service != ServiceDebug {
+				return nil, fmt.Errorf("unknown service %q of gRPC listener %q", service, l.Name)
+			}
+		}
+
+		opts := []grpc.ServerOption{
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+			grpc.ChainUnaryInterceptor(interceptors...),
+			grpc.ChainStreamInterceptor(streamInterceptors...),
+		}
+		if l.TLS && tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
+		gRPCServer := grpc.NewServer(opts...)
+
+		if slices.Contains(l.Services, ServiceAuth) {
+			authGRPC.Register(gRPCServer, log, authService, policyService)
+		}
+		if slices.Contains(l.Services, ServiceAdmin) {
+			adminGRPC.Register(gRPCServer, log, auditService, accessService, policyService, appService, logLevel)
+		}
+		if slices.Contains(l.Services, ServiceHealth) {
+			health.Register(gRPCServer)
+		}
+		if debug && slices.Contains(l.Services, ServiceDebug) {
+			reflection.Register(gRPCServer)
+			channelzservice.RegisterChannelzServiceToServer(gRPCServer)
+		}
+
+		s := server{
+			listener:   l,
+			gRPCServer: gRPCServer,
+		}
+		if l.Network == NetworkInProcess {
+			s.inProcess = bufconn.Listen(inProcessBufSize)
+		}
+
+		servers = append(servers, s)
 	}
+
+	return &App{
+		log:           log,
+		servers:       servers,
+		health:        health,
+		shutdownDelay: healthOpts.ShutdownDelay,
+	}, nil
 }
 
+// Run listens on every listener and serves requests until Stop is called or any of servers fails
 func (a *App) Run() error {
-	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
-	if err != nil {
-		return fmt.Errorf("failed to listen gRPC: %w", err)
+	listeners := make([]net.Listener, 0, len(a.servers))
+	for _, s := range a.servers {
+		l, err := s.listen()
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+
+			return fmt.Errorf("failed to listen gRPC %q: %w", s.listener.Name, err)
+		}
+
+		listeners = append(listeners, l)
 	}
 
-	a.log.Info("starting gRPC server", slog.String("address", l.Addr().String()))
+	go a.health.Run()
 
-	if err := a.gRPCServer.Serve(l); err != nil {
-		return fmt.Errorf("failed to serve gRPC: %w", err)
+	errs := make(chan error, len(a.servers))
+	for i, s := range a.servers {
+		a.log.Info("starting gRPC server",
+			slog.String("listener", s.listener.Name),
+			slog.String("address", listeners[i].Addr().String()),
+			slog.Any("services", s.listener.Services),
+		)
+
+		go func(s server, l net.Listener) {
+			if err := s.gRPCServer.Serve(l); err != nil {
+				errs <- fmt.Errorf("failed to serve gRPC %q: %w", s.listener.Name, err)
+				return
+			}
+			errs <- nil
+		}(s, listeners[i])
+	}
+
+	for range a.servers {
+		if err := <-errs; err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (a *App) Stop() {
-	a.log.Info("stopping gRPC server", slog.Int("port", a.port))
+	a.log.Info("stopping gRPC server")
+
+	a.health.Shutdown()
+	time.Sleep(a.shutdownDelay)
+
+	for _, s := range a.servers {
+		s.gRPCServer.GracefulStop()
+	}
+}
+
+// Conn returns client connection to in-process listener with given name
+func (a *App) Conn(name string) (*grpc.ClientConn, error) {
+	for _, s := range a.servers {
+		if s.listener.Name != name || s.inProcess == nil {
+			continue
+		}
+
+		l := s.inProcess
+		conn, err := grpc.NewClient(
+			"passthrough:///"+name,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return l.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-process gRPC client: %w", err)
+		}
+
+		return conn, nil
+	}
+
+	return nil, fmt.Errorf("in-process gRPC listener %q not found", name)
+}
+
+func (s server) listen() (net.Listener, error) {
+	if s.inProcess != nil {
+		return s.inProcess, nil
+	}
+
+	network, address := s.listener.Network, s.listener.Address
+	if network == "unix" {
+		// remove socket left by previous unclean shutdown
+		if info, err := os.Stat(address); err == nil && info.Mode().Type() == fs.ModeSocket {
+			if err := os.Remove(address); err != nil {
+				return nil, err
+			}
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
 
-	a.gRPCServer.GracefulStop()
+	return net.Listen(network, address)
 }
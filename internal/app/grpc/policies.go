@@ -0,0 +1,57 @@
+package grpcapp
+
+import (
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/interceptor"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// scopeAuthorize lets backend services check permissions of users of their app with client credentials tokens
+const scopeAuthorize = "sso:authorize"
+
+// policies declares who is allowed to call unary methods of every served service,
+// methods missing here are denied
+var policies = interceptor.Policies{
+	method(ssov1.Auth_ServiceDesc, "Register"):          interceptor.Public(),
+	method(ssov1.Auth_ServiceDesc, "Login"):             interceptor.Public(),
+	method(ssov1.Auth_ServiceDesc, "IsAdmin"):           interceptor.Admin(),
+	method(ssov1.Auth_ServiceDesc, "ListSessions"):      interceptor.Self(),
+	method(ssov1.Auth_ServiceDesc, "RevokeSession"):     interceptor.Self(),
+	method(ssov1.Auth_ServiceDesc, "RevokeAllSessions"): interceptor.Self(),
+	method(ssov1.Auth_ServiceDesc, "CheckPermission"):   interceptor.SelfOrScope(scopeAuthorize),
+	method(ssov1.Auth_ServiceDesc, "ListUserRoles"):     interceptor.Self(),
+	method(ssov1.Auth_ServiceDesc, "Authorize"):         interceptor.SelfOrScope(scopeAuthorize),
+
+	method(ssov1.Admin_ServiceDesc, "ListAuditEvents"): interceptor.GlobalPermission("audit:read"),
+	method(ssov1.Admin_ServiceDesc, "GetLogLevel"):     interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "SetLogLevel"):     interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "GrantAdmin"):      interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "RevokeAdmin"):     interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "GrantRole"):       interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "RevokeRole"):      interceptor.Admin(),
+	method(ssov1.Admin_ServiceDesc, "PutPolicy"):       interceptor.GlobalPermission("policies:manage"),
+	method(ssov1.Admin_ServiceDesc, "ListPolicies"):    interceptor.GlobalPermission("policies:manage"),
+	method(ssov1.Admin_ServiceDesc, "CreateApp"):       interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "UpdateApp"):       interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "ListApps"):        interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "DeleteApp"):       interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "RotateAppSecret"): interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "AddAppMember"):    interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "RemoveAppMember"): interceptor.GlobalPermission("apps:manage"),
+	method(ssov1.Admin_ServiceDesc, "ListAppMembers"):  interceptor.GlobalPermission("apps:manage"),
+
+	grpc_health_v1.Health_ServiceDesc.ServiceName: interceptor.Public(),
+
+	// debugging services are served only in debug mode on listeners exposing ServiceDebug
+	channelzpb.Channelz_ServiceDesc.ServiceName:                  interceptor.Admin(),
+	reflectionpb.ServerReflection_ServiceDesc.ServiceName:        interceptor.Admin(),
+	reflectionv1alphapb.ServerReflection_ServiceDesc.ServiceName: interceptor.Admin(),
+}
+
+func method(desc grpc.ServiceDesc, name string) string {
+	return "/" + desc.ServiceName + "/" + name
+}
@@ -0,0 +1,55 @@
+package grpcapp
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/interceptor"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+func TestPolicies_CoverAllMethods(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{ssov1.Auth_ServiceDesc, ssov1.Admin_ServiceDesc} {
+		for _, m := range desc.Methods {
+			if _, ok := policies.Lookup(method(desc, m.MethodName)); !ok {
+				t.Errorf("method %s has no access policy", method(desc, m.MethodName))
+			}
+		}
+	}
+}
+
+func TestPolicies_DebugServicesRequireAdmin(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{
+		channelzpb.Channelz_ServiceDesc,
+		reflectionpb.ServerReflection_ServiceDesc,
+		reflectionv1alphapb.ServerReflection_ServiceDesc,
+	} {
+		for _, name := range append(methodNames(desc.Methods), streamNames(desc.Streams)...) {
+			policy, ok := policies.Lookup(method(desc, name))
+			if !ok || policy.Kind != interceptor.PolicyAdmin {
+				t.Errorf("method %s doesn't require admin", method(desc, name))
+			}
+		}
+	}
+}
+
+func methodNames(methods []grpc.MethodDesc) []string {
+	names := make([]string, 0, len(methods))
+	for _, m := range methods {
+		names = append(names, m.MethodName)
+	}
+
+	return names
+}
+
+func streamNames(streams []grpc.StreamDesc) []string {
+	names := make([]string, 0, len(streams))
+	for _, s := range streams {
+		names = append(names, s.StreamName)
+	}
+
+	return names
+}
@@ -0,0 +1,64 @@
+package httpapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+type App struct {
+	log        *slog.Logger
+	name       string
+	httpServer *http.Server
+}
+
+func New(
+	log *slog.Logger,
+	name string,
+	handler http.Handler,
+	host string,
+	port int,
+) *App {
+	return &App{
+		log:  log,
+		name: name,
+		httpServer: &http.Server{
+			Addr:              net.JoinHostPort(host, strconv.Itoa(port)),
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func (a *App) Run() error {
+	l, err := net.Listen("tcp", a.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen http %q: %w", a.name, err)
+	}
+
+	a.log.Info("starting http server", slog.String("server", a.name), slog.String("address", l.Addr().String()))
+
+	if err := a.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve http %q: %w", a.name, err)
+	}
+
+	return nil
+}
+
+func (a *App) Stop() {
+	a.log.Info("stopping http server", slog.String("server", a.name), slog.String("address", a.httpServer.Addr))
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := a.httpServer.Shutdown(ctx); err != nil {
+		a.log.Error("failed to stop http server", slog.String("server", a.name), slog.String("error", err.Error()))
+	}
+}
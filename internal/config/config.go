@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,12 +11,33 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Environments the service runs in
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
 type Config struct {
 	Env             string        `yaml:"env"`
 	AccessTokenTtl  time.Duration `env-required:"true" yaml:"access_token_ttl"`
 	RefreshTokenTtl time.Duration `env-required:"true" yaml:"refresh_token_ttl"`
-	Postgres        Postgres      `env-required:"true" yaml:"postgres"`
-	GRPC            Grpc          `env-required:"true" yaml:"grpc"`
+	// TokenRoleClaims embeds roles and permissions of user in access tokens
+	TokenRoleClaims bool `yaml:"token_role_claims" env:"TOKEN_ROLE_CLAIMS"`
+	// TokenSigningKeyFile is a PEM encoded PKCS #8 ECDSA P-256 or Ed25519 private key access tokens are signed with,
+	// its public key is published at /.well-known/jwks.json of OAuth server
+	TokenSigningKeyFile string   `env-required:"true" yaml:"token_signing_key_file" env:"TOKEN_SIGNING_KEY_FILE"`
+	Postgres            Postgres `env-required:"true" yaml:"postgres"`
+	GRPC                Grpc     `env-required:"true" yaml:"grpc"`
+	Audit               Audit    `yaml:"audit"`
+	Log                 Log      `yaml:"log"`
+	Metrics             Metrics  `yaml:"metrics"`
+	Gateway             Gateway  `yaml:"gateway"`
+	Web                 Web      `yaml:"web"`
+	OAuth               OAuth    `yaml:"oauth"`
+	Tracing             Tracing  `yaml:"tracing"`
+	Health              Health   `yaml:"health"`
+	Debug               Debug    `yaml:"debug"`
 }
 
 type Postgres struct {
@@ -29,9 +51,147 @@ type Postgres struct {
 }
 
 type Grpc struct {
-	Host    string        `env-required:"true" yaml:"host"`
-	Port    int           `env-required:"true" yaml:"port"`
+	Host string `env-required:"true" yaml:"host"`
+	Port int    `env-required:"true" yaml:"port"`
+	// Services are services exposed on host:port ("auth", "admin", "health", "debug"),
+	// all except "debug" if empty
+	Services []string `yaml:"services"`
+	// Listeners are additional listeners with their own set of services
+	Listeners []GrpcListener `yaml:"listeners"`
+	// Timeout is a server side deadline of requests without client deadline
 	Timeout time.Duration `env-required:"true" yaml:"timeout"`
+	// MaxTimeout caps client deadlines, no cap if zero
+	MaxTimeout time.Duration `yaml:"max_timeout"`
+	// MethodTimeouts overrides timeouts of methods by full ("/package.Service/Method") or short ("Service/Method") name
+	MethodTimeouts map[string]MethodTimeout `yaml:"method_timeouts"`
+	TLS            GrpcTLS                  `yaml:"tls"`
+}
+
+type GrpcTLS struct {
+	Enabled  bool   `yaml:"enabled" env:"GRPC_TLS_ENABLED"`
+	CertFile string `yaml:"cert_file" env:"GRPC_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"GRPC_TLS_KEY_FILE"`
+	// ClientCAFile is a CA bundle client certificates are verified against, enables mutual TLS
+	ClientCAFile string `yaml:"client_ca_file" env:"GRPC_TLS_CLIENT_CA_FILE"`
+	// RequireClientCert rejects clients without certificate, otherwise it is verified only if presented
+	RequireClientCert bool `yaml:"require_client_cert"`
+	// AdminClients are client certificate identities allowed to call admin RPCs, not restricted if empty
+	AdminClients []string `yaml:"admin_clients"`
+}
+
+type GrpcListener struct {
+	Name string `yaml:"name"`
+	// Network is "tcp" or "unix"
+	Network string `yaml:"network" env-default:"tcp"`
+	// Address is host:port for tcp or socket path for unix network
+	Address string `yaml:"address"`
+	// TLS enables TLS on the listener, requires grpc.tls to be enabled
+	TLS      bool     `yaml:"tls"`
+	Services []string `yaml:"services"`
+}
+
+type MethodTimeout struct {
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxTimeout time.Duration `yaml:"max_timeout"`
+}
+
+type Audit struct {
+	// File is a path of JSON lines file audit events are additionally written to, disabled if empty
+	File string `yaml:"file" env:"AUDIT_FILE"`
+}
+
+// Log overrides default logger options of the env, see logger.DefaultOptions
+type Log struct {
+	// Level is one of debug, info, warn, error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is one of json, text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// Output is one of stdout, stderr, file, syslog
+	Output     string    `yaml:"output" env:"LOG_OUTPUT"`
+	AddSource  *bool     `yaml:"add_source"`
+	File       LogFile   `yaml:"file"`
+	Syslog     LogSyslog `yaml:"syslog"`
+	RedactKeys []string  `yaml:"redact_keys"`
+}
+
+type LogFile struct {
+	Path string `yaml:"path" env:"LOG_FILE"`
+	// MaxSize is a size of log file in megabytes before it gets rotated
+	MaxSize    int `yaml:"max_size"`
+	MaxBackups int `yaml:"max_backups"`
+	// MaxAge is a number of days to retain rotated log files
+	MaxAge   int  `yaml:"max_age"`
+	Compress bool `yaml:"compress"`
+}
+
+type LogSyslog struct {
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+	Tag     string `yaml:"tag"`
+}
+
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Host    string `yaml:"host" env:"METRICS_HOST"`
+	Port    int    `yaml:"port" env:"METRICS_PORT" env-default:"9090"`
+	Path    string `yaml:"path" env-default:"/metrics"`
+}
+
+// Gateway is HTTP/JSON gateway to gRPC services
+type Gateway struct {
+	Enabled bool   `yaml:"enabled" env:"GATEWAY_ENABLED"`
+	Host    string `yaml:"host" env:"GATEWAY_HOST"`
+	Port    int    `yaml:"port" env:"GATEWAY_PORT" env-default:"8080"`
+	// Services are services available through the gateway ("auth", "admin"), all if empty
+	Services []string `yaml:"services"`
+}
+
+// Web serves Auth service to browsers over gRPC-Web and Connect protocols
+type Web struct {
+	Enabled bool   `yaml:"enabled" env:"WEB_ENABLED"`
+	Host    string `yaml:"host" env:"WEB_HOST"`
+	Port    int    `yaml:"port" env:"WEB_PORT" env-default:"8081"`
+	CORS    CORS   `yaml:"cors"`
+}
+
+// OAuth is OAuth 2.0 authorization server serving authorization code flow with PKCE and client credentials grant
+type OAuth struct {
+	Enabled bool   `yaml:"enabled" env:"OAUTH_ENABLED"`
+	Host    string `yaml:"host" env:"OAUTH_HOST"`
+	Port    int    `yaml:"port" env:"OAUTH_PORT" env-default:"8082"`
+	// CodeTTL is a lifetime of authorization codes
+	CodeTTL time.Duration `yaml:"code_ttl" env-default:"1m"`
+}
+
+type CORS struct {
+	// AllowedOrigins are origins allowed to call the service from browser, "*" allows any origin
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age" env-default:"10m"`
+}
+
+// Debug exposes pprof to loopback clients, and gRPC reflection and channelz on gRPC listeners with "debug" service.
+// It can't be enabled in prod env
+type Debug struct {
+	Enabled bool   `yaml:"enabled" env:"DEBUG_ENABLED"`
+	Host    string `yaml:"host" env:"DEBUG_HOST" env-default:"localhost"`
+	Port    int    `yaml:"port" env:"DEBUG_PORT" env-default:"6060"`
+}
+
+type Tracing struct {
+	Enabled bool `yaml:"enabled" env:"TRACING_ENABLED"`
+	// Endpoint is host:port of OTLP gRPC collector
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	ServiceName string  `yaml:"service_name" env-default:"sso"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+type Health struct {
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"5s"`
+	CheckTimeout  time.Duration `yaml:"check_timeout" env-default:"1s"`
+	// ShutdownDelay is a time the server reports NOT_SERVING before it stops accepting requests
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env-default:"0s"`
 }
 
 // MustLoad loads config from .env and yaml file
@@ -62,7 +222,20 @@ func MustLoad(envPath string) *Config {
 		log.Fatalf("cannot read config: %s", err.Error())
 	}
 
+	if err = cfg.Validate(); err != nil {
+		log.Fatalf("invalid config: %s", err.Error())
+	}
+
 	cfg.Postgres.DSNTemplate = fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=%s", cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.User, cfg.Postgres.Database, cfg.Postgres.Password, cfg.Postgres.SSLMode)
 
 	return cfg
 }
+
+// Validate checks settings which can't be used together
+func (c *Config) Validate() error {
+	if c.Env == EnvProd && c.Debug.Enabled {
+		return errors.New("debug can't be enabled in prod env, it exposes pprof, gRPC reflection and channelz")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		debug   bool
+		wantErr bool
+	}{
+		{name: "debug in local env", env: EnvLocal, debug: true},
+		{name: "debug in dev env", env: EnvDev, debug: true},
+		{name: "prod env without debug", env: EnvProd},
+		{name: "debug in prod env", env: EnvProd, debug: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Env: tt.env, Debug: Debug{Enabled: tt.debug}}
+
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
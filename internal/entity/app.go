@@ -1,6 +1,64 @@
 package entity
 
+import (
+	"slices"
+	"time"
+)
+
+// Grant types apps may be allowed to use
+const (
+	// GrantPassword is a login with email and password through Login RPC
+	GrantPassword          = "password"
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// GrantTypes are all supported grant types
+var GrantTypes = []string{GrantPassword, GrantAuthorizationCode, GrantRefreshToken, GrantClientCredentials}
+
 type App struct {
 	ID   int
 	Name string
+	// MembershipRequired lets only members of the app log in to it
+	MembershipRequired bool
+	// ClientID is a public identifier of the app in OAuth flows
+	ClientID     string
+	RedirectURIs []string
+	GrantTypes   []string
+	// Scopes are granted to the app itself, tokens of client credentials grant are limited to them
+	Scopes []string
+	// Disabled app can't be used to log in, but keeps its data
+	Disabled bool
+	// Public app has no client secret and exchanges authorization codes with PKCE code verifier only
+	Public bool
+	// OwnerID is a user responsible for the app, 0 if app has no owner
+	OwnerID   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AppUpdate holds changed settings of app, nil fields are left unchanged
+type AppUpdate struct {
+	Name               *string
+	MembershipRequired *bool
+	RedirectURIs       *[]string
+	GrantTypes         *[]string
+	Scopes             *[]string
+	Disabled           *bool
+	Public             *bool
+	OwnerID            *int64
+}
+
+// AppMember is a user allowed to log in to app requiring membership
+type AppMember struct {
+	AppID     int
+	UserID    int64
+	Email     string
+	CreatedAt time.Time
+}
+
+// AllowsGrant checks if app is allowed to use grant type
+func (a App) AllowsGrant(grantType string) bool {
+	return slices.Contains(a.GrantTypes, grantType)
 }
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+const (
+	AuditEventRegister          = "register"
+	AuditEventLogin             = "login"
+	AuditEventAdminCheck        = "admin_check"
+	AuditEventPermissionCheck   = "permission_check"
+	AuditEventSessionRevoke     = "session_revoke"
+	AuditEventAllSessionsRevoke = "all_sessions_revoke"
+	AuditEventRoleGrant         = "role_grant"
+	AuditEventRoleRevoke        = "role_revoke"
+	AuditEventAuthorize         = "authorize"
+	AuditEventPolicyUpdate      = "policy_update"
+	AuditEventAppCreate         = "app_create"
+	AuditEventAppUpdate         = "app_update"
+	AuditEventAppDelete         = "app_delete"
+	AuditEventAppSecretRotate   = "app_secret_rotate"
+	AuditEventCodeExchange      = "code_exchange"
+	AuditEventClientToken       = "client_token"
+	AuditEventAppMemberAdd      = "app_member_add"
+	AuditEventAppMemberRemove   = "app_member_remove"
+)
+
+type AuditEvent struct {
+	ID        int64
+	Type      string
+	Success   bool
+	Reason    string
+	ActorID   int64
+	SubjectID int64
+	AppID     int
+	IP        string
+	RequestID string
+	Details   map[string]string
+	CreatedAt time.Time
+}
+
+type AuditEventFilter struct {
+	Type      string
+	ActorID   int64
+	SubjectID int64
+	AppID     int
+	Success   *bool
+	From      time.Time
+	To        time.Time
+	// BeforeID limits events to ones with id less than BeforeID, used as pagination cursor
+	BeforeID int64
+	Limit    int
+}
@@ -1,5 +0,0 @@
-package entity
-
-type Admin struct {
-	ID int
-}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// AuthorizationCode is a single use OAuth authorization code issued to the app for user
+type AuthorizationCode struct {
+	CodeHash string
+	AppID    int
+	UserID   int64
+	// RedirectURI is a redirect uri code was issued for, token request must present the same one
+	RedirectURI string
+	// CodeChallenge is base64url encoded SHA-256 of PKCE code verifier
+	CodeChallenge string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+// ActionLogin is an action policies are evaluated for on every login
+const ActionLogin = "login"
+
+// Policy is a version of CEL rule allowing or denying action, the latest version of policy is effective
+type Policy struct {
+	ID          int
+	Name        string
+	Version     int
+	Description string
+	Action      string
+	// AppID is an app policy applies to, zero if it applies to every app
+	AppID int
+	// Effect is PolicyEffectAllow or PolicyEffectDeny
+	Effect     string
+	Expression string
+	// DryRun policy is evaluated and logged but doesn't affect decisions
+	DryRun    bool
+	Enabled   bool
+	CreatedBy int64
+	CreatedAt time.Time
+}
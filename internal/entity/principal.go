@@ -0,0 +1,23 @@
+package entity
+
+// Principal is an authenticated user or app an access token was issued to
+type Principal struct {
+	// UserID is zero for app principals of client credentials tokens
+	UserID int64
+	Email  string
+	AppID  int
+	// SessionID is a session user access token was issued in, zero for app principals
+	SessionID int64
+	// Roles and Permissions are present only if tokens carry role claims
+	Roles       []string
+	Permissions []string
+	// ClientID is set only for app principals, they act on their own behalf without a user
+	ClientID string
+	// Scopes are scopes client credentials token was issued with
+	Scopes []string
+}
+
+// IsApp checks if principal is an app authenticated by client credentials token
+func (p Principal) IsApp() bool {
+	return p.ClientID != ""
+}
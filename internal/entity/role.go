@@ -0,0 +1,17 @@
+package entity
+
+const (
+	// PermissionAdmin is a permission of sso administrators
+	PermissionAdmin = "admin"
+	// RoleAdmin is a role granting PermissionAdmin
+	RoleAdmin = "admin"
+)
+
+type Role struct {
+	ID          int
+	Name        string
+	Description string
+	Permissions []string
+	// AppID is an app the role is assigned in, zero if it is assigned globally
+	AppID int
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type Session struct {
+	ID               int64
+	UserID           int64
+	AppID            int
+	IP               string
+	UserAgent        string
+	RefreshTokenHash string
+	CreatedAt        time.Time
+	LastSeenAt       time.Time
+	ExpiresAt        time.Time
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"time"
+)
+
+type hasher interface {
+	Hash(password string) (string, error)
+	Check(password string, hash string) bool
+}
+
+// Hasher measures duration of wrapped hasher operations
+type Hasher struct {
+	hasher  hasher
+	metrics *Metrics
+}
+
+func NewHasher(hasher hasher, metrics *Metrics) *Hasher {
+	return &Hasher{
+		hasher:  hasher,
+		metrics: metrics,
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	defer h.observe("hash", time.Now())
+
+	return h.hasher.Hash(password)
+}
+
+func (h *Hasher) Check(password string, hash string) bool {
+	defer h.observe("check", time.Now())
+
+	return h.hasher.Check(password, hash)
+}
+
+func (h *Hasher) observe(operation string, start time.Time) {
+	h.metrics.hashDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "sso"
+
+// Metrics holds prometheus collectors of the sso service
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	grpcRequests *prometheus.CounterVec
+	grpcLatency  *prometheus.HistogramVec
+	grpcPanics   *prometheus.CounterVec
+
+	registrations *prometheus.CounterVec
+	logins        *prometheus.CounterVec
+
+	hashDuration *prometheus.HistogramVec
+}
+
+// New creates Metrics registered in a new registry along with go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "Number of handled gRPC requests by method and status code.",
+		}, []string{"method", "code"}),
+		grpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled gRPC requests by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		grpcPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "panics_total",
+			Help:      "Number of panics recovered in gRPC handlers by method.",
+		}, []string{"method"}),
+		registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "Number of registration attempts by result.",
+		}, []string{"result", "reason"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Number of login attempts by result, failure reason and app, app is unknown if it doesn't exist.",
+		}, []string{"result", "reason", "app_id"}),
+		hashDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "password_hash_duration_seconds",
+			Help:      "Duration of password hashing and checking operations.",
+			Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.grpcRequests,
+		m.grpcLatency,
+		m.grpcPanics,
+		m.registrations,
+		m.logins,
+		m.hashDuration,
+	)
+
+	return m
+}
+
+// RegisterDB registers collector of db connection pool stats
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records handled gRPC request
+func (m *Metrics) ObserveRequest(method string, code string, latency time.Duration) {
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+	m.grpcLatency.WithLabelValues(method).Observe(latency.Seconds())
+}
+
+// ObservePanic records panic recovered in gRPC handler
+func (m *Metrics) ObservePanic(method string) {
+	m.grpcPanics.WithLabelValues(method).Inc()
+}
+
+// Write counts domain events, so Metrics can be used as an audit sink
+func (m *Metrics) Write(_ context.Context, event entity.AuditEvent) error {
+	switch event.Type {
+	case entity.AuditEventRegister:
+		m.registrations.WithLabelValues(result(event.Success), event.Reason).Inc()
+	case entity.AuditEventLogin:
+		m.logins.WithLabelValues(result(event.Success), event.Reason, appLabel(event.AppID)).Inc()
+	}
+
+	return nil
+}
+
+// appLabel returns app label of app id, login events carry id of existing app or zero
+// so the label is bounded by the number of apps
+func appLabel(appID int) string {
+	if appID == 0 {
+		return "unknown"
+	}
+
+	return strconv.Itoa(appID)
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+
+	return "failure"
+}
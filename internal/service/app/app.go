@@ -0,0 +1,454 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/4aykovski/grpc_auth_sso/internal/service/app")
+
+// clientIDSize is a size of generated client ids in bytes
+const clientIDSize = 16
+
+type appRepository interface {
+	GetApp(ctx context.Context, id int) (entity.App, error)
+	GetApps(ctx context.Context) ([]entity.App, error)
+	SaveApp(ctx context.Context, app entity.App, secretHash string) (int, error)
+	UpdateApp(ctx context.Context, id int, update entity.AppUpdate) error
+	SetAppSecretHash(ctx context.Context, id int, secretHash string) error
+	DeleteApp(ctx context.Context, id int) error
+	AddMember(ctx context.Context, appID int, userID int64) (bool, error)
+	RemoveMember(ctx context.Context, appID int, userID int64) (bool, error)
+	GetMembers(ctx context.Context, appID int) ([]entity.AppMember, error)
+}
+
+type secretGenerator interface {
+	GenerateSecret() (secret, hash string, err error)
+}
+
+type auditor interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+}
+
+type Service struct {
+	log *slog.Logger
+
+	appRepo appRepository
+	secrets secretGenerator
+
+	auditor auditor
+}
+
+// audit reasons of failed operations
+const (
+	reasonAppNotFound      = "app_not_found"
+	reasonAppAlreadyExists = "app_already_exists"
+	reasonPublicApp        = "public_app"
+	reasonUserNotFound     = "user_not_found"
+	reasonInternalError    = "internal_error"
+)
+
+var (
+	ErrAppNotFound      = errors.New("app not found")
+	ErrAppAlreadyExists = errors.New("app already exists")
+	ErrPublicApp        = errors.New("public app has no client secret")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+// New creates new app Service
+func New(
+	log *slog.Logger,
+	appRepo appRepository,
+	secrets secretGenerator,
+	auditor auditor,
+) *Service {
+	return &Service{
+		log:     log,
+		appRepo: appRepo,
+		secrets: secrets,
+		auditor: auditor,
+	}
+}
+
+type CreateAppDTO struct {
+	ActorId            int64
+	Name               string
+	RedirectURIs       []string
+	GrantTypes         []string
+	Scopes             []string
+	MembershipRequired bool
+	// Public app gets no client secret, it authenticates code exchanges with PKCE code verifier only
+	Public bool
+}
+
+// CreateApp registers new app with generated client id and secret
+//
+// The secret is returned only once, only its hash is stored. Public apps get no secret and empty one is returned.
+// App is allowed to use password grant if grant types are empty.
+// The actor creating the app becomes its owner
+//
+// If app with the same name already exists, returns error ErrAppAlreadyExists
+func (s *Service) CreateApp(ctx context.Context, dto CreateAppDTO) (_ entity.App, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "app.CreateApp")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:    entity.AuditEventAppCreate,
+		ActorID: dto.ActorId,
+		Details: map[string]string{"name": dto.Name},
+	}
+
+	var clientSecret, secretHash string
+	if !dto.Public {
+		clientSecret, secretHash, err = s.secrets.GenerateSecret()
+		if err != nil {
+			event.Reason = reasonInternalError
+			s.auditor.Record(ctx, event)
+			return entity.App{}, "", fmt.Errorf("failed to create app: %w", err)
+		}
+	}
+
+	clientID, err := generateClientID()
+	if err != nil {
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return entity.App{}, "", fmt.Errorf("failed to create app: %w", err)
+	}
+
+	grantTypes := dto.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{entity.GrantPassword}
+	}
+
+	app := entity.App{
+		Name:               dto.Name,
+		MembershipRequired: dto.MembershipRequired,
+		ClientID:           clientID,
+		RedirectURIs:       dto.RedirectURIs,
+		GrantTypes:         grantTypes,
+		Scopes:             dto.Scopes,
+		Public:             dto.Public,
+		OwnerID:            dto.ActorId,
+	}
+
+	id, err := s.appRepo.SaveApp(ctx, app, secretHash)
+	if err != nil {
+		event.Reason = reasonInternalError
+		if errors.Is(err, repository.ErrAppAlreadyExists) {
+			event.Reason = reasonAppAlreadyExists
+			err = ErrAppAlreadyExists
+		}
+		s.auditor.Record(ctx, event)
+		return entity.App{}, "", fmt.Errorf("failed to create app: %w", err)
+	}
+
+	event.Success = true
+	event.AppID = id
+	s.auditor.Record(ctx, event)
+
+	app, err = s.appRepo.GetApp(ctx, id)
+	if err != nil {
+		return entity.App{}, "", fmt.Errorf("failed to get created app: %w", err)
+	}
+
+	return app, clientSecret, nil
+}
+
+type UpdateAppDTO struct {
+	ActorId int64
+	AppId   int
+	// Update holds settings to change, settings missing in it are left unchanged
+	Update entity.AppUpdate
+}
+
+// UpdateApp changes settings of app set in dto.Update
+//
+// If app doesn't exist, returns error ErrAppNotFound
+// If app with the same name already exists, returns error ErrAppAlreadyExists
+// If new owner doesn't exist, returns error ErrUserNotFound
+func (s *Service) UpdateApp(ctx context.Context, dto UpdateAppDTO) (_ entity.App, err error) {
+	ctx, span := tracer.Start(ctx, "app.UpdateApp")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:    entity.AuditEventAppUpdate,
+		ActorID: dto.ActorId,
+		AppID:   dto.AppId,
+		Details: updateDetails(dto.Update),
+	}
+
+	err = s.appRepo.UpdateApp(ctx, dto.AppId, dto.Update)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return entity.App{}, fmt.Errorf("failed to update app: %w", err)
+	}
+
+	event.Success = true
+	s.auditor.Record(ctx, event)
+
+	app, err := s.appRepo.GetApp(ctx, dto.AppId)
+	if err != nil {
+		return entity.App{}, fmt.Errorf("failed to get updated app: %w", err)
+	}
+
+	return app, nil
+}
+
+type DeleteAppDTO struct {
+	ActorId int64
+	AppId   int
+}
+
+// DeleteApp deletes app with its sessions, memberships, role assignments and policies
+//
+// If app doesn't exist, returns error ErrAppNotFound
+func (s *Service) DeleteApp(ctx context.Context, dto DeleteAppDTO) (err error) {
+	ctx, span := tracer.Start(ctx, "app.DeleteApp")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:    entity.AuditEventAppDelete,
+		ActorID: dto.ActorId,
+		AppID:   dto.AppId,
+	}
+
+	err = s.appRepo.DeleteApp(ctx, dto.AppId)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return fmt.Errorf("failed to delete app: %w", err)
+	}
+
+	event.Success = true
+	s.auditor.Record(ctx, event)
+
+	return nil
+}
+
+type RotateAppSecretDTO struct {
+	ActorId int64
+	AppId   int
+}
+
+// RotateAppSecret replaces client secret of app with generated one and returns it,
+// the previous secret can no longer be used to authenticate the client
+//
+// If app doesn't exist, returns error ErrAppNotFound
+// If app is public, returns error ErrPublicApp
+func (s *Service) RotateAppSecret(ctx context.Context, dto RotateAppSecretDTO) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "app.RotateAppSecret")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:    entity.AuditEventAppSecretRotate,
+		ActorID: dto.ActorId,
+		AppID:   dto.AppId,
+	}
+
+	app, err := s.appRepo.GetApp(ctx, dto.AppId)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return "", fmt.Errorf("failed to rotate app secret: %w", err)
+	}
+
+	if app.Public {
+		event.Reason = reasonPublicApp
+		s.auditor.Record(ctx, event)
+		return "", fmt.Errorf("failed to rotate app secret: %w", ErrPublicApp)
+	}
+
+	clientSecret, secretHash, err := s.secrets.GenerateSecret()
+	if err != nil {
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return "", fmt.Errorf("failed to rotate app secret: %w", err)
+	}
+
+	err = s.appRepo.SetAppSecretHash(ctx, dto.AppId, secretHash)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return "", fmt.Errorf("failed to rotate app secret: %w", err)
+	}
+
+	event.Success = true
+	s.auditor.Record(ctx, event)
+
+	return clientSecret, nil
+}
+
+type AppMemberDTO struct {
+	ActorId int64
+	AppId   int
+	UserId  int64
+}
+
+// AddMember lets user log in to the app if it requires membership
+// and reports whether user wasn't a member before
+//
+// If app doesn't exist, returns error ErrAppNotFound
+// If user doesn't exist, returns error ErrUserNotFound
+func (s *Service) AddMember(ctx context.Context, dto AppMemberDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "app.AddMember")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventAppMemberAdd,
+		ActorID:   dto.ActorId,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+	}
+
+	added, err := s.appRepo.AddMember(ctx, dto.AppId, dto.UserId)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to add app member: %w", err)
+	}
+
+	event.Success = true
+	event.Details = map[string]string{"added": strconv.FormatBool(added)}
+	s.auditor.Record(ctx, event)
+
+	return added, nil
+}
+
+// RemoveMember removes user from members of the app and reports whether user was a member,
+// the user can't log in to the app or refresh sessions in it any more if it requires membership
+func (s *Service) RemoveMember(ctx context.Context, dto AppMemberDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "app.RemoveMember")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventAppMemberRemove,
+		ActorID:   dto.ActorId,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+	}
+
+	removed, err := s.appRepo.RemoveMember(ctx, dto.AppId, dto.UserId)
+	if err != nil {
+		err = s.fromRepositoryError(err, &event)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to remove app member: %w", err)
+	}
+
+	event.Success = true
+	event.Details = map[string]string{"removed": strconv.FormatBool(removed)}
+	s.auditor.Record(ctx, event)
+
+	return removed, nil
+}
+
+// ListMembers returns members of the app ordered from the earliest added
+//
+// If app doesn't exist, returns error ErrAppNotFound
+func (s *Service) ListMembers(ctx context.Context, appId int) (_ []entity.AppMember, err error) {
+	ctx, span := tracer.Start(ctx, "app.ListMembers")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = s.appRepo.GetApp(ctx, appId); err != nil {
+		if errors.Is(err, repository.ErrAppNotFound) {
+			err = ErrAppNotFound
+		}
+		return nil, fmt.Errorf("failed to list app members: %w", err)
+	}
+
+	members, err := s.appRepo.GetMembers(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app members: %w", err)
+	}
+
+	return members, nil
+}
+
+// ListApps returns all apps ordered by id
+func (s *Service) ListApps(ctx context.Context) (_ []entity.App, err error) {
+	ctx, span := tracer.Start(ctx, "app.ListApps")
+	defer func() { tracing.End(span, err) }()
+
+	apps, err := s.appRepo.GetApps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	return apps, nil
+}
+
+// updateDetails returns audit details of app update, they list updated settings along with new name, owner, disabled and public flags
+func updateDetails(update entity.AppUpdate) map[string]string {
+	details := make(map[string]string)
+
+	var fields []string
+	if update.Name != nil {
+		fields = append(fields, "name")
+		details["name"] = *update.Name
+	}
+	if update.RedirectURIs != nil {
+		fields = append(fields, "redirect_uris")
+	}
+	if update.GrantTypes != nil {
+		fields = append(fields, "grant_types")
+	}
+	if update.Scopes != nil {
+		fields = append(fields, "scopes")
+	}
+	if update.MembershipRequired != nil {
+		fields = append(fields, "membership_required")
+	}
+	if update.Disabled != nil {
+		fields = append(fields, "disabled")
+		details["disabled"] = strconv.FormatBool(*update.Disabled)
+	}
+	if update.Public != nil {
+		fields = append(fields, "public")
+		details["public"] = strconv.FormatBool(*update.Public)
+	}
+	if update.OwnerID != nil {
+		fields = append(fields, "owner_id")
+		details["owner_id"] = strconv.FormatInt(*update.OwnerID, 10)
+	}
+	details["fields"] = strings.Join(fields, ",")
+
+	return details
+}
+
+// fromRepositoryError converts repository error to service error and sets audit reason of event
+func (s *Service) fromRepositoryError(err error, event *entity.AuditEvent) error {
+	switch {
+	case errors.Is(err, repository.ErrAppNotFound):
+		event.Reason = reasonAppNotFound
+		return ErrAppNotFound
+	case errors.Is(err, repository.ErrAppAlreadyExists):
+		event.Reason = reasonAppAlreadyExists
+		return ErrAppAlreadyExists
+	case errors.Is(err, repository.ErrUserNotFound):
+		event.Reason = reasonUserNotFound
+		return ErrUserNotFound
+	default:
+		event.Reason = reasonInternalError
+		return err
+	}
+}
+
+func generateClientID() (string, error) {
+	raw := make([]byte, clientIDSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate client id: %w", err)
+	}
+
+	return hex.EncodeToString(raw), nil
+}
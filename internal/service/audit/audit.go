@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type eventRepository interface {
+	SaveEvent(ctx context.Context, event entity.AuditEvent) (int64, error)
+	GetEvents(ctx context.Context, filter entity.AuditEventFilter) ([]entity.AuditEvent, error)
+}
+
+type sink interface {
+	Write(ctx context.Context, event entity.AuditEvent) error
+}
+
+type Service struct {
+	log *slog.Logger
+
+	eventRepo eventRepository
+	sinks     []sink
+}
+
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// New creates new audit Service
+//
+// Events are always stored in eventRepo and additionally written to every sink
+func New(
+	log *slog.Logger,
+	eventRepo eventRepository,
+	sinks ...sink,
+) *Service {
+	return &Service{
+		log:       log,
+		eventRepo: eventRepo,
+		sinks:     sinks,
+	}
+}
+
+// Record appends event to the audit log
+//
+// Client IP and request id are taken from request meta in ctx.
+// Failures are logged and never returned, so auditing can't break the audited operation
+func (s *Service) Record(ctx context.Context, event entity.AuditEvent) {
+	meta := requestmeta.FromContext(ctx)
+	if event.IP == "" {
+		event.IP = meta.IP
+	}
+	if event.RequestID == "" {
+		event.RequestID = meta.RequestID
+	}
+	event.CreatedAt = time.Now()
+
+	log := logger.FromContext(ctx, s.log).With(slog.String("type", event.Type))
+
+	id, err := s.eventRepo.SaveEvent(ctx, event)
+	if err != nil {
+		log.Error("failed to save audit event", slog.String("error", err.Error()))
+	}
+	event.ID = id
+
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			log.Error("failed to write audit event", slog.String("error", err.Error()))
+		}
+	}
+}
+
+type ListEventsDTO struct {
+	Type      string
+	ActorId   int64
+	SubjectId int64
+	AppId     int
+	Success   *bool
+	From      time.Time
+	To        time.Time
+	PageSize  int
+	PageToken string
+}
+
+// ListEvents returns page of audit events matching dto from newest to oldest and token of the next page
+//
+// Next page token is empty on the last page.
+// If page token is malformed, returns error ErrInvalidPageToken
+func (s *Service) ListEvents(ctx context.Context, dto ListEventsDTO) ([]entity.AuditEvent, string, error) {
+	var beforeId int64
+	if dto.PageToken != "" {
+		id, err := strconv.ParseInt(dto.PageToken, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", fmt.Errorf("failed to list audit events: %w", ErrInvalidPageToken)
+		}
+		beforeId = id
+	}
+
+	pageSize := dto.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	events, err := s.eventRepo.GetEvents(ctx, entity.AuditEventFilter{
+		Type:      dto.Type,
+		ActorID:   dto.ActorId,
+		SubjectID: dto.SubjectId,
+		AppID:     dto.AppId,
+		Success:   dto.Success,
+		From:      dto.From,
+		To:        dto.To,
+		BeforeID:  beforeId,
+		Limit:     pageSize + 1,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	var nextPageToken string
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		nextPageToken = strconv.FormatInt(events[pageSize-1].ID, 10)
+	}
+
+	return events, nextPageToken, nil
+}
@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEventRepository struct {
+	saved   []entity.AuditEvent
+	saveErr error
+
+	events []entity.AuditEvent
+	filter entity.AuditEventFilter
+	getErr error
+}
+
+func (r *fakeEventRepository) SaveEvent(_ context.Context, event entity.AuditEvent) (int64, error) {
+	if r.saveErr != nil {
+		return 0, r.saveErr
+	}
+
+	r.saved = append(r.saved, event)
+	return int64(len(r.saved)), nil
+}
+
+func (r *fakeEventRepository) GetEvents(_ context.Context, filter entity.AuditEventFilter) ([]entity.AuditEvent, error) {
+	r.filter = filter
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+
+	return r.events[:min(len(r.events), filter.Limit)], nil
+}
+
+type fakeSink struct {
+	events []entity.AuditEvent
+	err    error
+}
+
+func (s *fakeSink) Write(_ context.Context, event entity.AuditEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func newTestService(repo *fakeEventRepository, sinks ...sink) *Service {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, sinks...)
+}
+
+func TestRecord(t *testing.T) {
+	repo := &fakeEventRepository{}
+	first := &fakeSink{err: errors.New("disk is full")}
+	second := &fakeSink{}
+	s := newTestService(repo, first, second)
+
+	ctx := requestmeta.WithMeta(context.Background(), requestmeta.Meta{RequestID: "req-1", IP: "10.0.0.1"})
+	s.Record(ctx, entity.AuditEvent{Type: entity.AuditEventLogin, Success: true, ActorID: 7})
+
+	require.Len(t, repo.saved, 1)
+	saved := repo.saved[0]
+	assert.Equal(t, "10.0.0.1", saved.IP)
+	assert.Equal(t, "req-1", saved.RequestID)
+	assert.False(t, saved.CreatedAt.IsZero())
+
+	// failing sink doesn't prevent writing to the next one
+	require.Len(t, first.events, 1)
+	require.Len(t, second.events, 1)
+	assert.Equal(t, int64(1), second.events[0].ID)
+	assert.Equal(t, int64(7), second.events[0].ActorID)
+}
+
+func TestRecord_KeepsExplicitMeta(t *testing.T) {
+	repo := &fakeEventRepository{}
+	s := newTestService(repo)
+
+	ctx := requestmeta.WithMeta(context.Background(), requestmeta.Meta{RequestID: "req-1", IP: "10.0.0.1"})
+	s.Record(ctx, entity.AuditEvent{Type: entity.AuditEventLogin, IP: "192.168.0.1", RequestID: "req-2"})
+
+	require.Len(t, repo.saved, 1)
+	assert.Equal(t, "192.168.0.1", repo.saved[0].IP)
+	assert.Equal(t, "req-2", repo.saved[0].RequestID)
+}
+
+func TestRecord_RepositoryError(t *testing.T) {
+	repo := &fakeEventRepository{saveErr: errors.New("connection refused")}
+	sink := &fakeSink{}
+	s := newTestService(repo, sink)
+
+	s.Record(context.Background(), entity.AuditEvent{Type: entity.AuditEventLogin})
+
+	// event is still written to sinks, so it isn't lost if database is down
+	require.Len(t, sink.events, 1)
+	assert.Zero(t, sink.events[0].ID)
+}
+
+func TestListEvents(t *testing.T) {
+	// repository returns events from newest to oldest
+	events := make([]entity.AuditEvent, 5)
+	for i := range events {
+		events[i] = entity.AuditEvent{ID: int64(len(events) - i)}
+	}
+
+	tests := []struct {
+		name          string
+		dto           ListEventsDTO
+		wantLen       int
+		wantLimit     int
+		wantBeforeId  int64
+		wantNextToken string
+	}{
+		{
+			name:      "default page size",
+			dto:       ListEventsDTO{},
+			wantLen:   5,
+			wantLimit: defaultPageSize + 1,
+		},
+		{
+			name:      "page size is capped",
+			dto:       ListEventsDTO{PageSize: maxPageSize + 100},
+			wantLen:   5,
+			wantLimit: maxPageSize + 1,
+		},
+		{
+			name:          "next page",
+			dto:           ListEventsDTO{PageSize: 2},
+			wantLen:       2,
+			wantLimit:     3,
+			wantNextToken: "4",
+		},
+		{
+			name:         "page token",
+			dto:          ListEventsDTO{PageSize: 10, PageToken: "4"},
+			wantLen:      5,
+			wantLimit:    11,
+			wantBeforeId: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEventRepository{events: events}
+			s := newTestService(repo)
+
+			got, next, err := s.ListEvents(context.Background(), tt.dto)
+			require.NoError(t, err)
+			assert.Len(t, got, tt.wantLen)
+			assert.Equal(t, tt.wantNextToken, next)
+			assert.Equal(t, tt.wantLimit, repo.filter.Limit)
+			assert.Equal(t, tt.wantBeforeId, repo.filter.BeforeID)
+		})
+	}
+}
+
+func TestListEvents_InvalidPageToken(t *testing.T) {
+	for _, token := range []string{"abc", "0", "-1", "4x"} {
+		t.Run(token, func(t *testing.T) {
+			s := newTestService(&fakeEventRepository{})
+
+			_, _, err := s.ListEvents(context.Background(), ListEventsDTO{PageToken: token})
+			assert.ErrorIs(t, err, ErrInvalidPageToken)
+		})
+	}
+}
+
+func TestListEvents_RepositoryError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+	s := newTestService(&fakeEventRepository{getErr: repoErr})
+
+	_, _, err := s.ListEvents(context.Background(), ListEventsDTO{})
+	assert.ErrorIs(t, err, repoErr)
+}
@@ -2,26 +2,59 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
 	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/internal/service/policy"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/4aykovski/grpc_auth_sso/internal/service/auth")
+
 type userRepository interface {
 	SaveUser(ctx context.Context, user entity.User) (int64, error)
 	GetUser(ctx context.Context, email string) (entity.User, error)
+	GetUserByID(ctx context.Context, id int64) (entity.User, error)
 }
 
-type adminRepository interface {
-	GetAdmin(ctx context.Context, userId int) (entity.Admin, error)
+type roleRepository interface {
+	GetUserRoles(ctx context.Context, userID int64, appID int) ([]entity.Role, error)
+	HasPermission(ctx context.Context, userID int64, permission string, appID int) (bool, error)
+	GrantRole(ctx context.Context, userID int64, role string, appID int) (bool, error)
+	RevokeRole(ctx context.Context, userID int64, role string, appID int) (bool, error)
 }
 
 type appRepository interface {
 	GetApp(ctx context.Context, appID int) (entity.App, error)
+	GetAppByClientID(ctx context.Context, clientID string) (entity.App, error)
+	IsMember(ctx context.Context, appID int, userID int64) (bool, error)
+}
+
+type sessionRepository interface {
+	SaveSession(ctx context.Context, session entity.Session) (int64, error)
+	GetUserSessions(ctx context.Context, userID int64) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID int64) error
+	RevokeUserSessions(ctx context.Context, userID int64) (int64, error)
+	IsSessionActive(ctx context.Context, sessionID int64) (bool, error)
+}
+
+type codeRepository interface {
+	SaveCode(ctx context.Context, code entity.AuthorizationCode) error
+	ConsumeCode(ctx context.Context, codeHash string) (entity.AuthorizationCode, error)
+}
+
+type auditor interface {
+	Record(ctx context.Context, event entity.AuditEvent)
 }
 
 type tokenManager interface {
@@ -29,13 +62,27 @@ type tokenManager interface {
 		ctx context.Context,
 		user entity.User,
 		app entity.App,
+		sessionID int64,
+		roles []entity.Role,
 		tokenTTL time.Duration,
-		secret string,
 	) (string, error)
+	GenerateClientToken(
+		ctx context.Context,
+		app entity.App,
+		scopes []string,
+		tokenTTL time.Duration,
+	) (string, error)
+	GenerateRefreshToken() (string, error)
+	ParseJWTToken(ctx context.Context, tokenString string) (entity.Principal, error)
+}
+
+type policyAuthorizer interface {
+	Authorize(ctx context.Context, dto policy.AuthorizeDTO) (policy.Decision, error)
 }
 
+// secretManager checks client secrets of apps, they are used only to authenticate OAuth clients
 type secretManager interface {
-	GetSecret(ctx context.Context, appID int) (string, error)
+	CheckSecret(ctx context.Context, appID int, secret string) (bool, error)
 }
 
 type hasher interface {
@@ -46,9 +93,14 @@ type hasher interface {
 type Service struct {
 	log *slog.Logger
 
-	userRepo  userRepository
-	appRepo   appRepository
-	adminRepo adminRepository
+	userRepo    userRepository
+	appRepo     appRepository
+	roleRepo    roleRepository
+	sessionRepo sessionRepository
+	codeRepo    codeRepository
+
+	auditor  auditor
+	policies policyAuthorizer
 
 	tokenManager  tokenManager
 	secretManager secretManager
@@ -56,13 +108,52 @@ type Service struct {
 
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	// codeTTL is a lifetime of OAuth authorization codes
+	codeTTL time.Duration
+	// tokenRoleClaims embeds user roles and permissions in access tokens
+	tokenRoleClaims bool
 }
 
+// audit reasons of failed operations
+const (
+	reasonUserNotFound      = "user_not_found"
+	reasonInvalidPassword   = "invalid_password"
+	reasonInvalidApp        = "invalid_app"
+	reasonUserAlreadyExists = "user_already_exists"
+	reasonNotAdmin          = "not_admin"
+	reasonPermissionDenied  = "permission_denied"
+	reasonNotAppMember      = "not_app_member"
+	reasonAppDisabled       = "app_disabled"
+	reasonGrantNotAllowed   = "grant_not_allowed"
+	reasonSessionNotFound   = "session_not_found"
+	reasonRoleNotFound      = "role_not_found"
+	reasonLastAdmin         = "last_admin"
+	reasonPolicyDenied      = "policy_denied"
+	reasonInvalidClient     = "invalid_client"
+	reasonInvalidGrant      = "invalid_grant"
+	reasonInvalidScope      = "invalid_scope"
+	reasonInternalError     = "internal_error"
+)
+
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidAppId       = errors.New("invalid appId")
 	ErrInvalidUserId      = errors.New("invalid userId")
 	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrInvalidSessionId   = errors.New("invalid sessionId")
+	ErrAppNotFound        = errors.New("app not found")
+	ErrNotAppMember       = errors.New("user is not a member of the app")
+	ErrAppDisabled        = errors.New("app is disabled")
+	ErrGrantNotAllowed    = errors.New("grant type is not allowed for the app")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrLastAdmin          = errors.New("last admin can't be revoked")
+	ErrLoginDenied        = errors.New("login denied by policy")
+	ErrInvalidClient      = errors.New("invalid client")
+	ErrInvalidRedirectURI = errors.New("redirect uri is not registered for the app")
+	ErrInvalidGrant       = errors.New("invalid or expired authorization grant")
+	ErrInvalidScope       = errors.New("scope is not granted to the app")
 )
 
 // New creates new auth Service
@@ -70,23 +161,35 @@ func New(
 	log *slog.Logger,
 	userRepo userRepository,
 	appRepo appRepository,
-	adminRepo adminRepository,
+	roleRepo roleRepository,
+	sessionRepo sessionRepository,
+	codeRepo codeRepository,
+	auditor auditor,
+	policies policyAuthorizer,
 	tokenManager tokenManager,
 	secretManager secretManager,
 	hasher hasher,
 	accessTokenTTL time.Duration,
 	refreshTokenTTL time.Duration,
+	codeTTL time.Duration,
+	tokenRoleClaims bool,
 ) *Service {
 	return &Service{
 		log:             log,
 		userRepo:        userRepo,
 		appRepo:         appRepo,
-		adminRepo:       adminRepo,
+		roleRepo:        roleRepo,
+		sessionRepo:     sessionRepo,
+		codeRepo:        codeRepo,
+		auditor:         auditor,
+		policies:        policies,
 		tokenManager:    tokenManager,
 		secretManager:   secretManager,
 		hasher:          hasher,
 		accessTokenTTL:  accessTokenTTL,
 		refreshTokenTTL: refreshTokenTTL,
+		codeTTL:         codeTTL,
+		tokenRoleClaims: tokenRoleClaims,
 	}
 }
 
@@ -96,54 +199,202 @@ type LoginDTO struct {
 	AppId    int
 }
 
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	SessionId    int64
+	// AccessTokenTTL is a lifetime of access token
+	AccessTokenTTL time.Duration
+}
+
 // Login checks if user with given credentials exists in the system
+// and starts new session for the user in the app
 //
 // If user exists, but password is incorrect, returns error ErrInvalidCredentials
 // If user doesn't exist, returns error ErrInvalidCredentials
 // If app doesn't exist, returns error ErrInvalidAppId
-func (s *Service) Login(ctx context.Context, dto LoginDTO) (string, error) {
-	user, err := s.userRepo.GetUser(ctx, dto.Email)
+// If app is disabled, returns error ErrAppDisabled
+// If app doesn't allow password grant, returns error ErrGrantNotAllowed
+// If app requires membership and user isn't its member, returns error ErrNotAppMember
+// If login policies of the app deny login, returns error ErrLoginDenied
+func (s *Service) Login(ctx context.Context, dto LoginDTO) (_ Tokens, err error) {
+	ctx, span := tracer.Start(ctx, "auth.Login")
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromContext(ctx, s.log)
+
+	// app is looked up before credentials are checked, so failures are audited with id of existing app only,
+	// but its absence is reported after the check to not reveal which apps exist faster than credentials
+	app, err := s.appRepo.GetApp(ctx, dto.AppId)
+	if err != nil && !errors.Is(err, repository.ErrAppNotFound) {
+		s.auditLoginFailure(ctx, 0, 0, reasonInternalError)
+		return Tokens{}, fmt.Errorf("can't login user: %w", err)
+	}
+	appNotFound := err != nil
+
+	user, err := s.checkCredentials(ctx, dto.Email, dto.Password, app.ID)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("can't login user: %w", err)
+	}
+
+	if appNotFound {
+		s.auditor.Record(ctx, entity.AuditEvent{
+			Type:    entity.AuditEventLogin,
+			Reason:  reasonInvalidApp,
+			ActorID: user.ID,
+			Details: map[string]string{"app_id": strconv.Itoa(dto.AppId)},
+		})
+		return Tokens{}, fmt.Errorf("can't login user: %w", ErrInvalidAppId)
+	}
+	log.Debug("app", slog.String("app", app.Name), slog.Int("appId", app.ID))
+
+	if err = s.checkAppLogin(ctx, user, app, entity.GrantPassword); err != nil {
+		return Tokens{}, fmt.Errorf("can't login user: %w", err)
+	}
+
+	tokens, err := s.issueTokens(ctx, user, app)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("can't login user: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// checkCredentials returns user with email if password matches, failures are audited as logins to the app,
+// appId must be id of existing app or zero
+//
+// If user doesn't exist or password is incorrect, returns error ErrInvalidCredentials
+func (s *Service) checkCredentials(ctx context.Context, email string, password string, appId int) (entity.User, error) {
+	log := logger.FromContext(ctx, s.log)
+
+	user, err := s.userRepo.GetUser(ctx, email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", fmt.Errorf("can't login user: %w", ErrInvalidCredentials)
+			s.auditLoginFailure(ctx, 0, appId, reasonUserNotFound)
+			return entity.User{}, ErrInvalidCredentials
 		}
 
-		return "", fmt.Errorf("can't login user: %w", err)
+		s.auditLoginFailure(ctx, 0, appId, reasonInternalError)
+		return entity.User{}, err
 	}
-	s.log.Debug("user", slog.Int("user", int(user.ID)))
+	log.Debug("user", slog.Int("user", int(user.ID)))
 
-	if ok := s.hasher.Check(dto.Password, user.PasswordHash); !ok {
-		return "", fmt.Errorf("can't login user: %w", ErrInvalidCredentials)
+	if ok := s.checkPassword(ctx, password, user.PasswordHash); !ok {
+		s.auditLoginFailure(ctx, user.ID, appId, reasonInvalidPassword)
+		return entity.User{}, ErrInvalidCredentials
 	}
 
-	app, err := s.appRepo.GetApp(ctx, dto.AppId)
-	if err != nil {
-		if errors.Is(err, repository.ErrAppNotFound) {
-			return "", fmt.Errorf("can't login user: %w", ErrInvalidAppId)
+	return user, nil
+}
+
+// checkAppLogin checks if user may log in to the app with grant type, failures are audited
+//
+// If app is disabled, returns error ErrAppDisabled
+// If app doesn't allow grant type, returns error ErrGrantNotAllowed
+// If app requires membership and user isn't its member, returns error ErrNotAppMember
+// If login policies of the app deny login, returns error ErrLoginDenied
+func (s *Service) checkAppLogin(ctx context.Context, user entity.User, app entity.App, grantType string) error {
+	if app.Disabled {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonAppDisabled)
+		return ErrAppDisabled
+	}
+
+	if !app.AllowsGrant(grantType) {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonGrantNotAllowed)
+		return ErrGrantNotAllowed
+	}
+
+	if app.MembershipRequired {
+		isMember, err := s.appRepo.IsMember(ctx, app.ID, user.ID)
+		if err != nil {
+			s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+			return err
 		}
 
-		return "", fmt.Errorf("can't login user: %w", err)
+		if !isMember {
+			s.auditLoginFailure(ctx, user.ID, app.ID, reasonNotAppMember)
+			return ErrNotAppMember
+		}
+	}
+
+	decision, err := s.policies.Authorize(ctx, policy.AuthorizeDTO{
+		UserId: user.ID,
+		AppId:  app.ID,
+		Action: entity.ActionLogin,
+	})
+	if err != nil {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return err
+	}
+
+	if decision.Applicable && !decision.Allowed {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonPolicyDenied)
+		return ErrLoginDenied
 	}
-	s.log.Debug("app", slog.String("app", app.Name), slog.Int("appId", app.ID))
 
-	secret, err := s.secretManager.GetSecret(ctx, dto.AppId)
+	return nil
+}
+
+// issueTokens starts new session of user in the app and returns its access and refresh tokens,
+// the login is audited
+func (s *Service) issueTokens(ctx context.Context, user entity.User, app entity.App) (_ Tokens, err error) {
+	refreshToken, err := s.tokenManager.GenerateRefreshToken()
 	if err != nil {
-		return "", fmt.Errorf("can't login user: %w", err)
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return Tokens{}, err
 	}
-	s.log.Debug("secret", slog.String("secret", secret))
 
-	token, err := s.tokenManager.GenerateJWTToken(
-		ctx,
-		user,
-		app,
-		s.accessTokenTTL,
-		secret,
-	)
+	meta := requestmeta.FromContext(ctx)
+	now := time.Now()
+
+	sessionId, err := s.sessionRepo.SaveSession(ctx, entity.Session{
+		UserID:           user.ID,
+		AppID:            app.ID,
+		IP:               meta.IP,
+		UserAgent:        meta.UserAgent,
+		RefreshTokenHash: hashToken(refreshToken),
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(s.refreshTokenTTL),
+	})
 	if err != nil {
-		return "", fmt.Errorf("can't login user: %w", err)
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return Tokens{}, err
 	}
 
-	return token, nil
+	token, err := s.accessToken(ctx, user, app, sessionId)
+	if err != nil {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return Tokens{}, err
+	}
+
+	s.auditor.Record(ctx, entity.AuditEvent{
+		Type:    entity.AuditEventLogin,
+		Success: true,
+		ActorID: user.ID,
+		AppID:   app.ID,
+		Details: map[string]string{"session_id": strconv.FormatInt(sessionId, 10)},
+	})
+
+	return Tokens{
+		AccessToken:    token,
+		RefreshToken:   refreshToken,
+		SessionId:      sessionId,
+		AccessTokenTTL: s.accessTokenTTL,
+	}, nil
+}
+
+// accessToken generates access token of user in session of the app, with role claims if they are enabled
+func (s *Service) accessToken(ctx context.Context, user entity.User, app entity.App, sessionId int64) (_ string, err error) {
+	var roles []entity.Role
+	if s.tokenRoleClaims {
+		roles, err = s.roleRepo.GetUserRoles(ctx, user.ID, app.ID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return s.tokenManager.GenerateJWTToken(ctx, user, app, sessionId, roles, s.accessTokenTTL)
 }
 
 type RegisterDTO struct {
@@ -154,8 +405,11 @@ type RegisterDTO struct {
 // Register creates new user in the system
 //
 // If user with the same email already exists, returns error ErrUserAlreadyExists
-func (s *Service) Register(ctx context.Context, dto RegisterDTO) (int64, error) {
-	passHash, err := s.hasher.Hash(dto.Password)
+func (s *Service) Register(ctx context.Context, dto RegisterDTO) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "auth.Register")
+	defer func() { tracing.End(span, err) }()
+
+	passHash, err := s.hashPassword(ctx, dto.Password)
 	if err != nil {
 		return -1, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -168,12 +422,16 @@ func (s *Service) Register(ctx context.Context, dto RegisterDTO) (int64, error)
 	id, err := s.userRepo.SaveUser(ctx, user)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventRegister, Reason: reasonUserAlreadyExists})
 			return -1, fmt.Errorf("failed to save user: %w", ErrUserAlreadyExists)
 		}
 
+		s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventRegister, Reason: reasonInternalError})
 		return -1, fmt.Errorf("failed to save user: %w", err)
 	}
 
+	s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventRegister, Success: true, ActorID: id})
+
 	return id, nil
 }
 
@@ -181,18 +439,406 @@ type IsAdminDTO struct {
 	UserId int
 }
 
-// IsAdmin checks if user is admin
+// IsAdmin checks if user has admin permission granted globally
+//
+// If user doesn't exist or isn't admin, returns error ErrInvalidUserId
+func (s *Service) IsAdmin(ctx context.Context, dto IsAdminDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "auth.IsAdmin")
+	defer func() { tracing.End(span, err) }()
+
+	isAdmin, err := s.roleRepo.HasPermission(ctx, int64(dto.UserId), entity.PermissionAdmin, 0)
+	if err != nil {
+		s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventAdminCheck, SubjectID: int64(dto.UserId), Reason: reasonInternalError})
+		return false, fmt.Errorf("failed to check if user is admin: %w", err)
+	}
+
+	if !isAdmin {
+		s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventAdminCheck, SubjectID: int64(dto.UserId), Reason: reasonNotAdmin})
+		return false, fmt.Errorf("failed to check if user is admin: %w", ErrInvalidUserId)
+	}
+
+	s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventAdminCheck, Success: true, SubjectID: int64(dto.UserId)})
+
+	return true, nil
+}
+
+type CheckPermissionDTO struct {
+	UserId     int64
+	Permission string
+	AppId      int
+}
+
+// CheckPermission checks if any global role of user or role assigned in the app grants permission
 //
+// If app doesn't exist, returns error ErrAppNotFound
+func (s *Service) CheckPermission(ctx context.Context, dto CheckPermissionDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "auth.CheckPermission")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventPermissionCheck,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+		Details:   map[string]string{"permission": dto.Permission},
+	}
+
+	_, err = s.appRepo.GetApp(ctx, dto.AppId)
+	if err != nil {
+		if errors.Is(err, repository.ErrAppNotFound) {
+			event.Reason = reasonInvalidApp
+			s.auditor.Record(ctx, event)
+			return false, fmt.Errorf("failed to check permission: %w", ErrAppNotFound)
+		}
+
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	allowed, err := s.roleRepo.HasPermission(ctx, dto.UserId, dto.Permission, dto.AppId)
+	if err != nil {
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	if !allowed {
+		event.Reason = reasonPermissionDenied
+	}
+	event.Success = allowed
+	s.auditor.Record(ctx, event)
+
+	return allowed, nil
+}
+
+// HasPermission checks if any global role of user or role assigned in the app grants permission,
+// only global roles are checked if appId is zero
+//
+// Unlike CheckPermission, it doesn't record audit events and is meant for authorizing requests
+func (s *Service) HasPermission(ctx context.Context, userId int64, permission string, appId int) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "auth.HasPermission")
+	defer func() { tracing.End(span, err) }()
+
+	allowed, err := s.roleRepo.HasPermission(ctx, userId, permission, appId)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return allowed, nil
+}
+
+type ListUserRolesDTO struct {
+	// AppId limits roles to ones effective in the app if set
+	AppId  int
+	UserId int64
+}
+
+// ListUserRoles returns roles of user with their permissions and apps they are assigned in
+func (s *Service) ListUserRoles(ctx context.Context, dto ListUserRolesDTO) (_ []entity.Role, err error) {
+	ctx, span := tracer.Start(ctx, "auth.ListUserRoles")
+	defer func() { tracing.End(span, err) }()
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, dto.UserId, dto.AppId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Authenticate verifies access token and returns principal it was issued to
+//
+// If token is malformed, expired, signed with another key, its app is deleted or disabled,
+// or session of user token is revoked or expired, returns error ErrInvalidToken
+func (s *Service) Authenticate(ctx context.Context, accessToken string) (_ entity.Principal, err error) {
+	ctx, span := tracer.Start(ctx, "auth.Authenticate")
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromContext(ctx, s.log)
+
+	principal, err := s.tokenManager.ParseJWTToken(ctx, accessToken)
+	if err != nil {
+		log.Debug("invalid access token", slog.String("error", err.Error()))
+		return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", ErrInvalidToken)
+	}
+
+	app, err := s.appRepo.GetApp(ctx, principal.AppID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAppNotFound) {
+			log.Debug("app of access token is deleted", slog.Int("appId", principal.AppID))
+			return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", ErrInvalidToken)
+		}
+
+		return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	if app.Disabled {
+		log.Debug("app of access token is disabled", slog.Int("appId", principal.AppID))
+		return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", ErrInvalidToken)
+	}
+
+	// access tokens of users stop working as soon as their session is revoked or expires
+	if !principal.IsApp() {
+		active, err := s.sessionRepo.IsSessionActive(ctx, principal.SessionID)
+		if err != nil {
+			return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", err)
+		}
+
+		if !active {
+			log.Debug("session of access token is not active", slog.Int64("sessionId", principal.SessionID))
+			return entity.Principal{}, fmt.Errorf("failed to authenticate: %w", ErrInvalidToken)
+		}
+	}
+
+	return principal, nil
+}
+
+type GrantRoleDTO struct {
+	// ActorId is a user granting the role, it must have admin permission granted globally
+	ActorId int64
+	UserId  int64
+	Role    string
+	// AppId is an app role is granted in, role is granted globally if zero
+	AppId int
+}
+
+// GrantRole assigns role to user and reports whether user didn't have it before
+//
+// If actor isn't admin, returns error ErrPermissionDenied
+// If role doesn't exist, returns error ErrRoleNotFound
 // If user doesn't exist, returns error ErrInvalidUserId
-func (s *Service) IsAdmin(ctx context.Context, dto IsAdminDTO) (bool, error) {
-	_, err := s.adminRepo.GetAdmin(ctx, dto.UserId)
+// If app doesn't exist, returns error ErrAppNotFound
+func (s *Service) GrantRole(ctx context.Context, dto GrantRoleDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "auth.GrantRole")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventRoleGrant,
+		ActorID:   dto.ActorId,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+		Details:   map[string]string{"role": dto.Role},
+	}
+
+	if err = s.requireAdmin(ctx, dto.ActorId); err != nil {
+		event.Reason = auditReason(err)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	granted, err := s.roleRepo.GrantRole(ctx, dto.UserId, dto.Role, dto.AppId)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return false, fmt.Errorf("failed to check if user is admin: %w", ErrInvalidUserId)
+		err = fromRoleRepositoryError(err)
+		event.Reason = auditReason(err)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	event.Success = true
+	event.Details["granted"] = strconv.FormatBool(granted)
+	s.auditor.Record(ctx, event)
+
+	return granted, nil
+}
+
+type RevokeRoleDTO struct {
+	// ActorId is a user revoking the role, it must have admin permission granted globally
+	ActorId int64
+	UserId  int64
+	Role    string
+	// AppId is an app role was granted in, zero if it was granted globally
+	AppId int
+}
+
+// RevokeRole removes role from user and reports whether user had it
+//
+// If actor isn't admin, returns error ErrPermissionDenied
+// If role doesn't exist, returns error ErrRoleNotFound
+// If revocation would leave no admins, returns error ErrLastAdmin
+func (s *Service) RevokeRole(ctx context.Context, dto RevokeRoleDTO) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "auth.RevokeRole")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventRoleRevoke,
+		ActorID:   dto.ActorId,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+		Details:   map[string]string{"role": dto.Role},
+	}
+
+	if err = s.requireAdmin(ctx, dto.ActorId); err != nil {
+		event.Reason = auditReason(err)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	revoked, err := s.roleRepo.RevokeRole(ctx, dto.UserId, dto.Role, dto.AppId)
+	if err != nil {
+		err = fromRoleRepositoryError(err)
+		event.Reason = auditReason(err)
+		s.auditor.Record(ctx, event)
+		return false, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	event.Success = true
+	event.Details["revoked"] = strconv.FormatBool(revoked)
+	s.auditor.Record(ctx, event)
+
+	return revoked, nil
+}
+
+// requireAdmin returns ErrPermissionDenied if user doesn't have admin permission granted globally
+func (s *Service) requireAdmin(ctx context.Context, userId int64) error {
+	isAdmin, err := s.roleRepo.HasPermission(ctx, userId, entity.PermissionAdmin, 0)
+	if err != nil {
+		return fmt.Errorf("failed to check if user is admin: %w", err)
+	}
+
+	if !isAdmin {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// fromRoleRepositoryError converts errors of role assignments to service errors
+func fromRoleRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRoleNotFound):
+		return ErrRoleNotFound
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrInvalidUserId
+	case errors.Is(err, repository.ErrAppNotFound):
+		return ErrAppNotFound
+	case errors.Is(err, repository.ErrLastAdmin):
+		return ErrLastAdmin
+	default:
+		return err
+	}
+}
+
+// auditReason returns audit reason of role management failure
+func auditReason(err error) string {
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		return reasonPermissionDenied
+	case errors.Is(err, ErrRoleNotFound):
+		return reasonRoleNotFound
+	case errors.Is(err, ErrInvalidUserId):
+		return reasonUserNotFound
+	case errors.Is(err, ErrAppNotFound):
+		return reasonInvalidApp
+	case errors.Is(err, ErrLastAdmin):
+		return reasonLastAdmin
+	default:
+		return reasonInternalError
+	}
+}
+
+type ListSessionsDTO struct {
+	UserId int64
+}
+
+// ListSessions returns active sessions of user
+func (s *Service) ListSessions(ctx context.Context, dto ListSessionsDTO) (_ []entity.Session, err error) {
+	ctx, span := tracer.Start(ctx, "auth.ListSessions")
+	defer func() { tracing.End(span, err) }()
+
+	sessions, err := s.sessionRepo.GetUserSessions(ctx, dto.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+type RevokeSessionDTO struct {
+	UserId    int64
+	SessionId int64
+}
+
+// RevokeSession revokes user session, so its refresh token can't be used anymore
+//
+// If session doesn't exist, belongs to another user or is already revoked, returns error ErrInvalidSessionId
+func (s *Service) RevokeSession(ctx context.Context, dto RevokeSessionDTO) (err error) {
+	ctx, span := tracer.Start(ctx, "auth.RevokeSession")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventSessionRevoke,
+		SubjectID: dto.UserId,
+		Details:   map[string]string{"session_id": strconv.FormatInt(dto.SessionId, 10)},
+	}
+
+	err = s.sessionRepo.RevokeSession(ctx, dto.UserId, dto.SessionId)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			event.Reason = reasonSessionNotFound
+			s.auditor.Record(ctx, event)
+			return fmt.Errorf("failed to revoke session: %w", ErrInvalidSessionId)
 		}
 
-		return false, fmt.Errorf("failed to check if user is admin: %w", err)
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return fmt.Errorf("failed to revoke session: %w", err)
 	}
 
-	return true, nil
+	event.Success = true
+	s.auditor.Record(ctx, event)
+
+	return nil
+}
+
+type RevokeAllSessionsDTO struct {
+	UserId int64
+}
+
+// RevokeAllSessions revokes all active sessions of user and returns their count
+func (s *Service) RevokeAllSessions(ctx context.Context, dto RevokeAllSessionsDTO) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "auth.RevokeAllSessions")
+	defer func() { tracing.End(span, err) }()
+
+	revoked, err := s.sessionRepo.RevokeUserSessions(ctx, dto.UserId)
+	if err != nil {
+		s.auditor.Record(ctx, entity.AuditEvent{Type: entity.AuditEventAllSessionsRevoke, SubjectID: dto.UserId, Reason: reasonInternalError})
+		return -1, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.auditor.Record(ctx, entity.AuditEvent{
+		Type:      entity.AuditEventAllSessionsRevoke,
+		Success:   true,
+		SubjectID: dto.UserId,
+		Details:   map[string]string{"revoked": strconv.FormatInt(revoked, 10)},
+	})
+
+	return revoked, nil
+}
+
+func (s *Service) auditLoginFailure(ctx context.Context, userId int64, appId int, reason string) {
+	s.auditor.Record(ctx, entity.AuditEvent{
+		Type:    entity.AuditEventLogin,
+		Reason:  reason,
+		ActorID: userId,
+		AppID:   appId,
+	})
+}
+
+func (s *Service) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracer.Start(ctx, "hasher.Hash")
+	defer span.End()
+
+	return s.hasher.Hash(password)
+}
+
+func (s *Service) checkPassword(ctx context.Context, password string, hash string) bool {
+	_, span := tracer.Start(ctx, "hasher.Check")
+	defer span.End()
+
+	return s.hasher.Check(password, hash)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
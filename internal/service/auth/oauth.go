@@ -0,0 +1,336 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+)
+
+// authorizationCodeSize is a size of generated authorization codes in bytes
+const authorizationCodeSize = 32
+
+// GetClient returns app of OAuth client which may redirect user to redirectURI
+//
+// If app doesn't exist or is disabled, returns error ErrInvalidClient
+// If redirect uri isn't registered for the app, returns error ErrInvalidRedirectURI
+func (s *Service) GetClient(ctx context.Context, clientId string, redirectURI string) (_ entity.App, err error) {
+	ctx, span := tracer.Start(ctx, "auth.GetClient")
+	defer func() { tracing.End(span, err) }()
+
+	app, err := s.getClient(ctx, clientId)
+	if err != nil {
+		return entity.App{}, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if !slices.Contains(app.RedirectURIs, redirectURI) {
+		return entity.App{}, fmt.Errorf("failed to get client: %w", ErrInvalidRedirectURI)
+	}
+
+	return app, nil
+}
+
+type IssueCodeDTO struct {
+	Email       string
+	Password    string
+	ClientId    string
+	RedirectURI string
+	// CodeChallenge is base64url encoded SHA-256 of PKCE code verifier
+	CodeChallenge string
+}
+
+// IssueAuthorizationCode checks credentials of user and issues single use authorization code
+// bound to the client, redirect uri and PKCE code challenge
+//
+// If app doesn't exist or is disabled, returns error ErrInvalidClient
+// If redirect uri isn't registered for the app, returns error ErrInvalidRedirectURI
+// If user doesn't exist or password is incorrect, returns error ErrInvalidCredentials
+// If app doesn't allow authorization code grant, returns error ErrGrantNotAllowed
+// If app requires membership and user isn't its member, returns error ErrNotAppMember
+// If login policies of the app deny login, returns error ErrLoginDenied
+func (s *Service) IssueAuthorizationCode(ctx context.Context, dto IssueCodeDTO) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "auth.IssueAuthorizationCode")
+	defer func() { tracing.End(span, err) }()
+
+	app, err := s.GetClient(ctx, dto.ClientId, dto.RedirectURI)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue authorization code: %w", err)
+	}
+
+	user, err := s.checkCredentials(ctx, dto.Email, dto.Password, app.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue authorization code: %w", err)
+	}
+
+	if err = s.checkAppLogin(ctx, user, app, entity.GrantAuthorizationCode); err != nil {
+		return "", fmt.Errorf("failed to issue authorization code: %w", err)
+	}
+
+	code, err := generateAuthorizationCode()
+	if err != nil {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return "", fmt.Errorf("failed to issue authorization code: %w", err)
+	}
+
+	now := time.Now()
+	err = s.codeRepo.SaveCode(ctx, entity.AuthorizationCode{
+		CodeHash:      hashToken(code),
+		AppID:         app.ID,
+		UserID:        user.ID,
+		RedirectURI:   dto.RedirectURI,
+		CodeChallenge: dto.CodeChallenge,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.codeTTL),
+	})
+	if err != nil {
+		s.auditLoginFailure(ctx, user.ID, app.ID, reasonInternalError)
+		return "", fmt.Errorf("failed to issue authorization code: %w", err)
+	}
+
+	return code, nil
+}
+
+type ExchangeCodeDTO struct {
+	ClientId string
+	// ClientSecret is required unless app is public, public apps are authenticated by PKCE code verifier only
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+// ExchangeAuthorizationCode exchanges authorization code for tokens of new session of user
+// the code was issued for
+//
+// If app doesn't exist, is disabled, or isn't public and client secret is missing or doesn't match,
+// returns error ErrInvalidClient
+// If app doesn't allow authorization code grant, returns error ErrGrantNotAllowed
+// If code doesn't exist, is expired, was issued to another client or redirect uri,
+// or code verifier doesn't match code challenge, returns error ErrInvalidGrant
+func (s *Service) ExchangeAuthorizationCode(ctx context.Context, dto ExchangeCodeDTO) (_ Tokens, err error) {
+	ctx, span := tracer.Start(ctx, "auth.ExchangeAuthorizationCode")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{Type: entity.AuditEventCodeExchange}
+
+	app, err := s.getClient(ctx, dto.ClientId)
+	if err != nil {
+		event.Reason = reasonInternalError
+		if errors.Is(err, ErrInvalidClient) {
+			event.Reason = reasonInvalidClient
+		}
+		s.auditor.Record(ctx, event)
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	event.AppID = app.ID
+
+	if !app.Public {
+		if err = s.checkClientSecret(ctx, app, dto.ClientSecret); err != nil {
+			event.Reason = reasonInternalError
+			if errors.Is(err, ErrInvalidClient) {
+				event.Reason = reasonInvalidClient
+			}
+			s.auditor.Record(ctx, event)
+			return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", err)
+		}
+	}
+
+	if !app.AllowsGrant(entity.GrantAuthorizationCode) {
+		event.Reason = reasonGrantNotAllowed
+		s.auditor.Record(ctx, event)
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", ErrGrantNotAllowed)
+	}
+
+	code, err := s.codeRepo.ConsumeCode(ctx, hashToken(dto.Code))
+	if err != nil {
+		event.Reason = reasonInternalError
+		if errors.Is(err, repository.ErrCodeNotFound) {
+			event.Reason = reasonInvalidGrant
+			err = ErrInvalidGrant
+		}
+		s.auditor.Record(ctx, event)
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	event.ActorID = code.UserID
+
+	if code.AppID != app.ID ||
+		code.RedirectURI != dto.RedirectURI ||
+		time.Now().After(code.ExpiresAt) ||
+		!verifyCodeChallenge(dto.CodeVerifier, code.CodeChallenge) {
+		event.Reason = reasonInvalidGrant
+		s.auditor.Record(ctx, event)
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", ErrInvalidGrant)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, code.UserID)
+	if err != nil {
+		event.Reason = reasonInternalError
+		if errors.Is(err, repository.ErrUserNotFound) {
+			event.Reason = reasonInvalidGrant
+			err = ErrInvalidGrant
+		}
+		s.auditor.Record(ctx, event)
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	tokens, err := s.issueTokens(ctx, user, app)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return tokens, nil
+}
+
+type ClientCredentialsDTO struct {
+	ClientId     string
+	ClientSecret string
+	// Scopes are requested scopes, all scopes granted to the app if empty
+	Scopes []string
+}
+
+type ClientToken struct {
+	AccessToken string
+	// Scopes are scopes the token was issued with
+	Scopes []string
+	// AccessTokenTTL is a lifetime of access token
+	AccessTokenTTL time.Duration
+}
+
+// IssueClientToken authenticates app by its client id and secret and issues access token of the app itself
+// limited to requested scopes, no refresh token is issued
+//
+// If app doesn't exist, is disabled or client secret doesn't match, returns error ErrInvalidClient
+// If app doesn't allow client credentials grant, returns error ErrGrantNotAllowed
+// If any requested scope isn't granted to the app, returns error ErrInvalidScope
+func (s *Service) IssueClientToken(ctx context.Context, dto ClientCredentialsDTO) (_ ClientToken, err error) {
+	ctx, span := tracer.Start(ctx, "auth.IssueClientToken")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{Type: entity.AuditEventClientToken}
+
+	app, err := s.getClient(ctx, dto.ClientId)
+	if err == nil {
+		event.AppID = app.ID
+		err = s.checkClientSecret(ctx, app, dto.ClientSecret)
+	}
+	if err != nil {
+		event.Reason = reasonInternalError
+		if errors.Is(err, ErrInvalidClient) {
+			event.Reason = reasonInvalidClient
+		}
+		s.auditor.Record(ctx, event)
+		return ClientToken{}, fmt.Errorf("failed to issue client token: %w", err)
+	}
+
+	if !app.AllowsGrant(entity.GrantClientCredentials) {
+		event.Reason = reasonGrantNotAllowed
+		s.auditor.Record(ctx, event)
+		return ClientToken{}, fmt.Errorf("failed to issue client token: %w", ErrGrantNotAllowed)
+	}
+
+	scopes, ok := grantedScopes(dto.Scopes, app.Scopes)
+	if !ok {
+		event.Reason = reasonInvalidScope
+		s.auditor.Record(ctx, event)
+		return ClientToken{}, fmt.Errorf("failed to issue client token: %w", ErrInvalidScope)
+	}
+	event.Details = map[string]string{"scope": strings.Join(scopes, " ")}
+
+	token, err := s.tokenManager.GenerateClientToken(ctx, app, scopes, s.accessTokenTTL)
+	if err != nil {
+		event.Reason = reasonInternalError
+		s.auditor.Record(ctx, event)
+		return ClientToken{}, fmt.Errorf("failed to issue client token: %w", err)
+	}
+
+	event.Success = true
+	s.auditor.Record(ctx, event)
+
+	return ClientToken{
+		AccessToken:    token,
+		Scopes:         scopes,
+		AccessTokenTTL: s.accessTokenTTL,
+	}, nil
+}
+
+// getClient returns enabled app with client id
+//
+// If app doesn't exist or is disabled, returns error ErrInvalidClient
+func (s *Service) getClient(ctx context.Context, clientId string) (entity.App, error) {
+	app, err := s.appRepo.GetAppByClientID(ctx, clientId)
+	if err != nil {
+		if errors.Is(err, repository.ErrAppNotFound) {
+			return entity.App{}, ErrInvalidClient
+		}
+
+		return entity.App{}, err
+	}
+
+	if app.Disabled {
+		return entity.App{}, ErrInvalidClient
+	}
+
+	return app, nil
+}
+
+// checkClientSecret compares secret with client secret of the app
+//
+// If secret is empty or doesn't match, or app has no secret, returns error ErrInvalidClient
+func (s *Service) checkClientSecret(ctx context.Context, app entity.App, secret string) error {
+	ok, err := s.secretManager.CheckSecret(ctx, app.ID, secret)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInvalidClient
+	}
+
+	return nil
+}
+
+// grantedScopes returns sorted unique requested scopes if all of them are allowed,
+// or all allowed scopes if none requested
+func grantedScopes(requested []string, allowed []string) ([]string, bool) {
+	if len(requested) == 0 {
+		return allowed, true
+	}
+
+	for _, scope := range requested {
+		if !slices.Contains(allowed, scope) {
+			return nil, false
+		}
+	}
+
+	scopes := slices.Clone(requested)
+	slices.Sort(scopes)
+
+	return slices.Compact(scopes), true
+}
+
+// verifyCodeChallenge checks PKCE code verifier against S256 code challenge
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func generateAuthorizationCode() (string, error) {
+	b := make([]byte, authorizationCodeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// example of RFC 7636 appendix B
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+
+	assert.True(t, verifyCodeChallenge(verifier, challenge))
+	assert.False(t, verifyCodeChallenge(verifier+"x", challenge))
+	assert.False(t, verifyCodeChallenge(verifier, ""))
+	assert.False(t, verifyCodeChallenge("", challenge))
+}
+
+func TestGrantedScopes(t *testing.T) {
+	allowed := []string{"orders:read", "orders:write"}
+
+	tests := []struct {
+		name      string
+		requested []string
+		want      []string
+		ok        bool
+	}{
+		{name: "all allowed if none requested", requested: nil, want: allowed, ok: true},
+		{name: "subset", requested: []string{"orders:read"}, want: []string{"orders:read"}, ok: true},
+		{name: "duplicates", requested: []string{"orders:write", "orders:read", "orders:write"}, want: allowed, ok: true},
+		{name: "not granted", requested: []string{"orders:read", "users:read"}, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := grantedScopes(tt.requested, allowed)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/google/cel-go/cel"
+)
+
+// engine compiles CEL expressions of policies and caches programs by policy version
+//
+// Expressions are evaluated against variables:
+//
+//	user     map: id int, email string, roles list(string), permissions list(string)
+//	app      map: id int, name string, owner_id int (0 if app has no owner)
+//	resource map(string, string) of attributes of the requested resource
+//	request  map: ip string, user_agent string
+//	action   string
+//	now      timestamp
+//
+// Evaluation of a program is aborted once its cost exceeds costLimit,
+// so an expensive expression can't stall requests checking policies
+type engine struct {
+	env *cel.Env
+
+	mu       sync.RWMutex
+	programs map[string]cel.Program
+}
+
+const (
+	// costLimit caps cost of evaluating one expression, it is far above cost of expressions over the attributes
+	// and is reached only by nested comprehensions over large lists
+	costLimit = 100_000
+	// interruptCheckFrequency is a number of comprehension iterations between checks of context cancellation
+	interruptCheckFrequency = 100
+)
+
+func newEngine() (*engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("app", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("action", cel.StringType),
+		cel.Variable("now", cel.TimestampType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	return &engine{
+		env:      env,
+		programs: make(map[string]cel.Program),
+	}, nil
+}
+
+// compile checks expression and returns its program, expression must evaluate to bool
+func (e *engine) compile(expression string) (cel.Program, error) {
+	ast, issues := e.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression must evaluate to bool, got %s", t)
+	}
+
+	return e.env.Program(ast,
+		cel.CostLimit(costLimit),
+		cel.InterruptCheckFrequency(interruptCheckFrequency),
+	)
+}
+
+// program returns cached program of policy version
+func (e *engine) program(policy entity.Policy) (cel.Program, error) {
+	key := policy.Name + "@" + strconv.Itoa(policy.Version)
+
+	e.mu.RLock()
+	prg, ok := e.programs[key]
+	e.mu.RUnlock()
+	if ok {
+		return prg, nil
+	}
+
+	prg, err := e.compile(policy.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.programs[key] = prg
+	e.mu.Unlock()
+
+	return prg, nil
+}
+
+// match reports whether expression of policy evaluates to true against input,
+// evaluation is aborted if ctx is done or cost limit is exceeded
+func (e *engine) match(ctx context.Context, policy entity.Policy, in input) (bool, error) {
+	prg, err := e.program(policy)
+	if err != nil {
+		return false, fmt.Errorf("failed to compile policy %s: %w", policy.Name, err)
+	}
+
+	out, _, err := prg.ContextEval(ctx, in.activation())
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate policy %s: %w", policy.Name, err)
+	}
+
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("policy %s evaluated to %s instead of bool", policy.Name, out.Type())
+	}
+
+	return matched, nil
+}
+
+// input is a set of attributes policies are evaluated against
+type input struct {
+	user     entity.User
+	roles    []entity.Role
+	app      entity.App
+	resource map[string]string
+	ip       string
+	agent    string
+	action   string
+	now      time.Time
+}
+
+func (in input) activation() map[string]any {
+	roles := make([]string, 0, len(in.roles))
+	permissions := make([]string, 0, len(in.roles))
+	for _, role := range in.roles {
+		roles = append(roles, role.Name)
+		permissions = append(permissions, role.Permissions...)
+	}
+
+	resource := in.resource
+	if resource == nil {
+		resource = map[string]string{}
+	}
+
+	return map[string]any{
+		"user": map[string]any{
+			"id":          in.user.ID,
+			"email":       in.user.Email,
+			"roles":       roles,
+			"permissions": permissions,
+		},
+		"app": map[string]any{
+			"id":       int64(in.app.ID),
+			"name":     in.app.Name,
+			"owner_id": in.app.OwnerID,
+		},
+		"resource": resource,
+		"request": map[string]any{
+			"ip":         in.ip,
+			"user_agent": in.agent,
+		},
+		"action": in.action,
+		"now":    in.now,
+	}
+}
@@ -0,0 +1,381 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/repository"
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/4aykovski/grpc_auth_sso/pkg/logger"
+	"github.com/4aykovski/grpc_auth_sso/pkg/requestmeta"
+	"github.com/4aykovski/grpc_auth_sso/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/4aykovski/grpc_auth_sso/internal/service/policy")
+
+type policyRepository interface {
+	SavePolicy(ctx context.Context, policy entity.Policy) (entity.Policy, error)
+	GetEffectivePolicies(ctx context.Context, action string, appID int) ([]entity.Policy, error)
+	GetPolicies(ctx context.Context, name string) ([]entity.Policy, error)
+}
+
+type userRepository interface {
+	GetUserByID(ctx context.Context, id int64) (entity.User, error)
+}
+
+type roleRepository interface {
+	GetUserRoles(ctx context.Context, userID int64, appID int) ([]entity.Role, error)
+}
+
+type appRepository interface {
+	GetApp(ctx context.Context, appID int) (entity.App, error)
+}
+
+type auditor interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+}
+
+type Service struct {
+	log *slog.Logger
+
+	policyRepo policyRepository
+	userRepo   userRepository
+	roleRepo   roleRepository
+	appRepo    appRepository
+
+	auditor auditor
+
+	engine *engine
+}
+
+// audit reasons of denied decisions and failed operations
+const (
+	reasonPolicyDenied  = "policy_denied"
+	reasonNoPolicy      = "no_policy"
+	reasonInvalidPolicy = "invalid_policy"
+	reasonInvalidApp    = "invalid_app"
+	reasonUserNotFound  = "user_not_found"
+	reasonConflict      = "conflict"
+	reasonInternalError = "internal_error"
+)
+
+var (
+	ErrInvalidPolicy  = errors.New("invalid policy")
+	ErrPolicyConflict = errors.New("policy was changed concurrently")
+	ErrAppNotFound    = errors.New("app not found")
+	ErrUserNotFound   = errors.New("user not found")
+)
+
+// ExpressionError is an error of compiling CEL expression of policy, it matches ErrInvalidPolicy
+type ExpressionError struct {
+	Err error
+}
+
+func (e *ExpressionError) Error() string        { return "invalid expression: " + e.Err.Error() }
+func (e *ExpressionError) Unwrap() error        { return e.Err }
+func (e *ExpressionError) Is(target error) bool { return target == ErrInvalidPolicy }
+
+// New creates new policy Service
+func New(
+	log *slog.Logger,
+	policyRepo policyRepository,
+	userRepo userRepository,
+	roleRepo roleRepository,
+	appRepo appRepository,
+	auditor auditor,
+) (*Service, error) {
+	engine, err := newEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		log:        log,
+		policyRepo: policyRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		appRepo:    appRepo,
+		auditor:    auditor,
+		engine:     engine,
+	}, nil
+}
+
+type AuthorizeDTO struct {
+	UserId   int64
+	AppId    int
+	Action   string
+	Resource map[string]string
+}
+
+// Decision is a result of evaluating policies of action
+type Decision struct {
+	Allowed bool
+	// Applicable is false if no enforced policy applies to the action, such decisions are never allowed
+	Applicable bool
+	// Policy and Version identify the policy that made decision, Policy is empty if decision wasn't made by one policy
+	Policy  string
+	Version int
+	// DryRunAllowed is a decision that would be made if dry run policies were enforced
+	DryRunAllowed bool
+}
+
+// Authorize evaluates policies of action applying to the app against attributes of user, app, roles and request
+// and records decision in the audit log
+//
+// Action is denied if any matching deny policy, otherwise it is allowed if any allow policy matches
+// or only deny policies apply. Deny policies failing to evaluate are considered matching.
+// If no policy applies, action is denied.
+// Decisions of logins no policy applies to aren't recorded as logins are audited on their own.
+//
+// If app doesn't exist, returns error ErrAppNotFound
+// If user doesn't exist, returns error ErrUserNotFound
+func (s *Service) Authorize(ctx context.Context, dto AuthorizeDTO) (_ Decision, err error) {
+	ctx, span := tracer.Start(ctx, "policy.Authorize")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:      entity.AuditEventAuthorize,
+		SubjectID: dto.UserId,
+		AppID:     dto.AppId,
+		Details:   map[string]string{"action": dto.Action},
+	}
+
+	decision, err := s.authorize(ctx, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAppNotFound):
+			event.Reason = reasonInvalidApp
+		case errors.Is(err, ErrUserNotFound):
+			event.Reason = reasonUserNotFound
+		default:
+			event.Reason = reasonInternalError
+		}
+		s.auditor.Record(ctx, event)
+		return Decision{}, fmt.Errorf("failed to authorize: %w", err)
+	}
+
+	if !decision.Applicable && dto.Action == entity.ActionLogin {
+		return decision, nil
+	}
+
+	event.Success = decision.Allowed
+	if !decision.Applicable {
+		event.Reason = reasonNoPolicy
+	} else if !decision.Allowed {
+		event.Reason = reasonPolicyDenied
+	}
+	if decision.Policy != "" {
+		event.Details["policy"] = decision.Policy
+		event.Details["version"] = strconv.Itoa(decision.Version)
+	}
+	event.Details["dry_run_allowed"] = strconv.FormatBool(decision.DryRunAllowed)
+	s.auditor.Record(ctx, event)
+
+	return decision, nil
+}
+
+func (s *Service) authorize(ctx context.Context, dto AuthorizeDTO) (Decision, error) {
+	app, err := s.appRepo.GetApp(ctx, dto.AppId)
+	if err != nil {
+		if errors.Is(err, repository.ErrAppNotFound) {
+			return Decision{}, ErrAppNotFound
+		}
+
+		return Decision{}, err
+	}
+
+	policies, err := s.policyRepo.GetEffectivePolicies(ctx, dto.Action, dto.AppId)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	if len(policies) == 0 {
+		return Decision{}, nil
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, dto.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return Decision{}, ErrUserNotFound
+		}
+
+		return Decision{}, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, dto.UserId, dto.AppId)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	meta := requestmeta.FromContext(ctx)
+
+	return s.decide(ctx, policies, input{
+		user:     user,
+		roles:    roles,
+		app:      app,
+		resource: dto.Resource,
+		ip:       meta.IP,
+		agent:    meta.UserAgent,
+		action:   dto.Action,
+		now:      time.Now(),
+	}), nil
+}
+
+// verdict accumulates matching policies of one mode
+type verdict struct {
+	applicable bool
+	hasAllow   bool
+	allow      *entity.Policy
+	deny       *entity.Policy
+}
+
+func (v *verdict) add(policy *entity.Policy, matched bool) {
+	v.applicable = true
+	if policy.Effect == entity.PolicyEffectAllow {
+		v.hasAllow = true
+	}
+
+	if !matched {
+		return
+	}
+	if policy.Effect == entity.PolicyEffectDeny && v.deny == nil {
+		v.deny = policy
+	}
+	if policy.Effect == entity.PolicyEffectAllow && v.allow == nil {
+		v.allow = policy
+	}
+}
+
+func (v *verdict) allowed() bool {
+	return v.applicable && v.deny == nil && (v.allow != nil || !v.hasAllow)
+}
+
+func (s *Service) decide(ctx context.Context, policies []entity.Policy, in input) Decision {
+	log := logger.FromContext(ctx, s.log)
+
+	var enforced, dryRun verdict
+	for i := range policies {
+		policy := &policies[i]
+
+		matched, err := s.engine.match(ctx, *policy, in)
+		if err != nil {
+			log.Warn("failed to evaluate policy",
+				slog.String("policy", policy.Name),
+				slog.Int("version", policy.Version),
+				slog.String("error", err.Error()),
+			)
+			matched = policy.Effect == entity.PolicyEffectDeny
+		}
+
+		dryRun.add(policy, matched)
+		if !policy.DryRun {
+			enforced.add(policy, matched)
+		}
+	}
+
+	decision := Decision{
+		Allowed:       enforced.allowed(),
+		Applicable:    enforced.applicable,
+		DryRunAllowed: dryRun.allowed(),
+	}
+	if by := enforced.deny; by != nil {
+		decision.Policy, decision.Version = by.Name, by.Version
+	} else if by := enforced.allow; by != nil {
+		decision.Policy, decision.Version = by.Name, by.Version
+	}
+
+	if decision.DryRunAllowed != decision.Allowed {
+		log.Info("dry run policies change decision",
+			slog.String("action", in.action),
+			slog.Bool("allowed", decision.Allowed),
+			slog.Bool("dryRunAllowed", decision.DryRunAllowed),
+		)
+	}
+
+	return decision
+}
+
+type PutPolicyDTO struct {
+	ActorId     int64
+	Name        string
+	Description string
+	Action      string
+	AppId       int
+	Effect      string
+	Expression  string
+	DryRun      bool
+	Enabled     bool
+}
+
+// PutPolicy saves new version of policy, it becomes effective immediately
+//
+// If expression doesn't compile or doesn't evaluate to bool, returns *ExpressionError matching ErrInvalidPolicy
+// If app doesn't exist, returns error ErrAppNotFound
+// If policy was saved concurrently, returns error ErrPolicyConflict
+func (s *Service) PutPolicy(ctx context.Context, dto PutPolicyDTO) (_ entity.Policy, err error) {
+	ctx, span := tracer.Start(ctx, "policy.PutPolicy")
+	defer func() { tracing.End(span, err) }()
+
+	event := entity.AuditEvent{
+		Type:    entity.AuditEventPolicyUpdate,
+		ActorID: dto.ActorId,
+		AppID:   dto.AppId,
+		Details: map[string]string{"policy": dto.Name},
+	}
+
+	if _, err = s.engine.compile(dto.Expression); err != nil {
+		event.Reason = reasonInvalidPolicy
+		s.auditor.Record(ctx, event)
+		return entity.Policy{}, fmt.Errorf("failed to put policy: %w", &ExpressionError{Err: err})
+	}
+
+	policy, err := s.policyRepo.SavePolicy(ctx, entity.Policy{
+		Name:        dto.Name,
+		Description: dto.Description,
+		Action:      dto.Action,
+		AppID:       dto.AppId,
+		Effect:      dto.Effect,
+		Expression:  dto.Expression,
+		DryRun:      dto.DryRun,
+		Enabled:     dto.Enabled,
+		CreatedBy:   dto.ActorId,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAppNotFound):
+			event.Reason = reasonInvalidApp
+			err = ErrAppNotFound
+		case errors.Is(err, repository.ErrPolicyConflict):
+			event.Reason = reasonConflict
+			err = ErrPolicyConflict
+		default:
+			event.Reason = reasonInternalError
+		}
+		s.auditor.Record(ctx, event)
+		return entity.Policy{}, fmt.Errorf("failed to put policy: %w", err)
+	}
+
+	event.Success = true
+	event.Details["version"] = strconv.Itoa(policy.Version)
+	s.auditor.Record(ctx, event)
+
+	return policy, nil
+}
+
+// ListPolicies returns the latest versions of all policies, or all versions of policy if name isn't empty
+func (s *Service) ListPolicies(ctx context.Context, name string) (_ []entity.Policy, err error) {
+	ctx, span := tracer.Start(ctx, "policy.ListPolicies")
+	defer func() { tracing.End(span, err) }()
+
+	policies, err := s.policyRepo.GetPolicies(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	return policies, nil
+}
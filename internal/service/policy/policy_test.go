@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecide(t *testing.T) {
+	engine, err := newEngine()
+	require.NoError(t, err)
+
+	s := &Service{log: slog.New(slog.NewTextHandler(io.Discard, nil)), engine: engine}
+
+	in := input{
+		user:     entity.User{ID: 7, Email: "owner@example.com"},
+		roles:    []entity.Role{{Name: "editor", Permissions: []string{"apps:edit"}}},
+		app:      entity.App{ID: 1, Name: "test", OwnerID: 7},
+		resource: map[string]string{"owner_id": "7"},
+		action:   "apps:manage",
+		now:      time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC),
+	}
+
+	owner := entity.Policy{Name: "owner", Version: 2, Effect: entity.PolicyEffectAllow, Expression: `resource.owner_id == string(user.id)`}
+	appOwner := entity.Policy{Name: "app-owner", Version: 1, Effect: entity.PolicyEffectAllow, Expression: `app.owner_id == user.id`}
+	editor := entity.Policy{Name: "editor", Version: 1, Effect: entity.PolicyEffectAllow, Expression: `"apps:edit" in user.permissions`}
+	stranger := entity.Policy{Name: "stranger", Version: 1, Effect: entity.PolicyEffectAllow, Expression: `resource.owner_id == "1"`}
+	nightDeny := entity.Policy{Name: "night", Version: 1, Effect: entity.PolicyEffectDeny, Expression: `now.getHours() >= 20`}
+	dayDeny := entity.Policy{Name: "day", Version: 1, Effect: entity.PolicyEffectDeny, Expression: `now.getHours() < 8`}
+	broken := entity.Policy{Name: "broken", Version: 1, Effect: entity.PolicyEffectDeny, Expression: `resource.missing == "x"`}
+	costly := entity.Policy{Name: "costly", Version: 1, Effect: entity.PolicyEffectDeny, Expression: nestedComprehension(6)}
+
+	dryRun := func(p entity.Policy) entity.Policy {
+		p.DryRun = true
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		policies []entity.Policy
+		want     Decision
+	}{
+		{
+			name:     "matching allow",
+			policies: []entity.Policy{owner},
+			want:     Decision{Allowed: true, Applicable: true, Policy: "owner", Version: 2, DryRunAllowed: true},
+		},
+		{
+			name:     "owner of app",
+			policies: []entity.Policy{appOwner},
+			want:     Decision{Allowed: true, Applicable: true, Policy: "app-owner", Version: 1, DryRunAllowed: true},
+		},
+		{
+			name:     "no matching allow",
+			policies: []entity.Policy{stranger},
+			want:     Decision{Applicable: true},
+		},
+		{
+			name:     "deny overrides allow",
+			policies: []entity.Policy{editor, nightDeny},
+			want:     Decision{Applicable: true, Policy: "night", Version: 1},
+		},
+		{
+			name:     "only not matching deny",
+			policies: []entity.Policy{dayDeny},
+			want:     Decision{Allowed: true, Applicable: true, DryRunAllowed: true},
+		},
+		{
+			name:     "failing deny matches",
+			policies: []entity.Policy{owner, broken},
+			want:     Decision{Applicable: true, Policy: "broken", Version: 1},
+		},
+		{
+			name:     "deny exceeding cost limit matches",
+			policies: []entity.Policy{owner, costly},
+			want:     Decision{Applicable: true, Policy: "costly", Version: 1},
+		},
+		{
+			name:     "dry run deny isn't enforced",
+			policies: []entity.Policy{owner, dryRun(nightDeny)},
+			want:     Decision{Allowed: true, Applicable: true, Policy: "owner", Version: 2},
+		},
+		{
+			name:     "only dry run policies",
+			policies: []entity.Policy{dryRun(owner)},
+			want:     Decision{DryRunAllowed: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.decide(context.Background(), tt.policies, in))
+		})
+	}
+}
+
+func TestEngine_Compile(t *testing.T) {
+	engine, err := newEngine()
+	require.NoError(t, err)
+
+	_, err = engine.compile(`"admin" in user.roles && app.name == "test"`)
+	assert.NoError(t, err)
+
+	_, err = engine.compile(`user.id + 1`)
+	assert.Error(t, err, "expression must evaluate to bool")
+
+	_, err = engine.compile(`unknown == 1`)
+	assert.Error(t, err, "undeclared variable")
+}
+
+func TestEngine_CostLimit(t *testing.T) {
+	engine, err := newEngine()
+	require.NoError(t, err)
+
+	cheap := entity.Policy{Name: "cheap", Version: 1, Expression: nestedComprehension(2)}
+	matched, err := engine.match(context.Background(), cheap, input{})
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	// a million iterations exceed the cost limit
+	costly := entity.Policy{Name: "costly", Version: 1, Expression: nestedComprehension(6)}
+	_, err = engine.match(context.Background(), costly, input{})
+	assert.ErrorContains(t, err, "cost limit exceeded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = engine.match(ctx, cheap, input{})
+	assert.Error(t, err, "canceled evaluation")
+}
+
+// nestedComprehension returns expression iterating over a list of 10 elements depth times nested,
+// it makes 10^depth iterations and evaluates to true
+func nestedComprehension(depth int) string {
+	const list = "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]"
+
+	expression := "true"
+	for i := range depth {
+		expression = fmt.Sprintf("%s.all(x%d, %s)", list, i, expression)
+	}
+
+	return expression
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS membership_required BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS app_members (
+  app_id INT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (app_id, user_id)
+);
+
+-- role assignment without app is global and applies to every app
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey;
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS app_id INT REFERENCES apps(id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS user_roles_user_role_app_idx ON user_roles (user_id, role_id, COALESCE(app_id, 0));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM user_roles WHERE app_id IS NOT NULL;
+DROP INDEX IF EXISTS user_roles_user_role_app_idx;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS app_id;
+ALTER TABLE user_roles ADD PRIMARY KEY (user_id, role_id);
+
+DROP TABLE IF EXISTS app_members;
+
+ALTER TABLE apps DROP COLUMN IF EXISTS membership_required;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS policies (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  version INT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  action TEXT NOT NULL,
+  -- policy without app applies to every app
+  app_id INT REFERENCES apps(id) ON DELETE CASCADE,
+  effect TEXT NOT NULL CHECK (effect IN ('allow', 'deny')),
+  expression TEXT NOT NULL,
+  -- dry run policy is evaluated and logged but doesn't affect decisions
+  dry_run BOOLEAN NOT NULL DEFAULT false,
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  created_by INT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (name, version)
+);
+
+CREATE INDEX IF NOT EXISTS policies_action_idx ON policies (action);
+
+INSERT INTO permissions (name, description)
+VALUES ('policies:manage', 'Create and update authorization policies')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'policies:manage'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'policies:manage';
+
+DROP TABLE IF EXISTS policies;
+-- +goose StatementEnd
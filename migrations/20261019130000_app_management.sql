@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE apps
+  ADD COLUMN IF NOT EXISTS client_id TEXT NOT NULL DEFAULT replace(gen_random_uuid()::text, '-', ''),
+  -- client secret encrypted with app secret key, apps without it sign tokens with APP<id>_SECRET env
+  ADD COLUMN IF NOT EXISTS client_secret TEXT,
+  ADD COLUMN IF NOT EXISTS redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS grant_types TEXT[] NOT NULL DEFAULT '{password}',
+  ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE UNIQUE INDEX IF NOT EXISTS apps_client_id_idx ON apps (client_id);
+
+INSERT INTO permissions (name, description)
+VALUES ('apps:manage', 'Register and manage apps')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'apps:manage'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'apps:manage';
+
+DROP INDEX IF EXISTS apps_client_id_idx;
+
+ALTER TABLE apps
+  DROP COLUMN IF EXISTS client_id,
+  DROP COLUMN IF EXISTS client_secret,
+  DROP COLUMN IF EXISTS redirect_uris,
+  DROP COLUMN IF EXISTS grant_types,
+  DROP COLUMN IF EXISTS disabled,
+  DROP COLUMN IF EXISTS created_at,
+  DROP COLUMN IF EXISTS updated_at;
+-- +goose StatementEnd
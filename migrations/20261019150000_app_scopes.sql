@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- scopes are granted to the app itself, client credentials tokens are limited to them
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE apps DROP COLUMN IF EXISTS scopes;
+-- +goose StatementEnd
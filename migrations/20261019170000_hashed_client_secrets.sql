@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- client secrets are stored as SHA-256 hashes and are used only to authenticate OAuth clients,
+-- encrypted secrets can't be hashed here, so apps that had them must rotate their secrets
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS client_secret_hash TEXT;
+ALTER TABLE apps DROP COLUMN IF EXISTS client_secret;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS client_secret TEXT;
+ALTER TABLE apps DROP COLUMN IF EXISTS client_secret_hash;
+-- +goose StatementEnd
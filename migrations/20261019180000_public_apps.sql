@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- public apps can't keep a client secret, they exchange authorization codes with PKCE code verifier only,
+-- existing apps stay confidential and must authenticate with their secret
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS public BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE apps DROP COLUMN IF EXISTS public;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- owner of app is exposed to policies as app.owner_id, existing apps have no owner
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE apps DROP COLUMN IF EXISTS owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,136 @@
+// Package i18n provides message catalog with locale negotiation
+//
+// Catalogs are loaded from locales/<language>.json, English catalog is a fallback
+// for messages missing in other ones
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Default is a language of messages when client didn't ask for supported one
+var Default = language.English
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	catalogs  map[language.Tag]map[string]string
+	supported []language.Tag
+	matcher   language.Matcher
+)
+
+func init() {
+	var err error
+	catalogs, err = load()
+	if err != nil {
+		panic(err)
+	}
+
+	supported = []language.Tag{Default}
+	for tag := range catalogs {
+		if tag != Default {
+			supported = append(supported, tag)
+		}
+	}
+	matcher = language.NewMatcher(supported)
+}
+
+func load() (map[language.Tag]map[string]string, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locales: %w", err)
+	}
+
+	result := make(map[language.Tag]map[string]string, len(files))
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(file.Name(), path.Ext(file.Name())))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse locale %s: %w", file.Name(), err)
+		}
+
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale %s: %w", file.Name(), err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal locale %s: %w", file.Name(), err)
+		}
+
+		result[tag] = messages
+	}
+
+	if _, ok := result[Default]; !ok {
+		return nil, fmt.Errorf("catalog of default language %s is missing", Default)
+	}
+
+	return result, nil
+}
+
+// Supported returns languages having message catalog, Default is the first one
+func Supported() []language.Tag {
+	return supported
+}
+
+// Match returns supported language best matching Accept-Language header value
+func Match(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+
+	return supported[index]
+}
+
+// Translate returns message of key in lang formatted with args,
+// message of Default language is used if lang has no such message and key itself if no language has it
+func Translate(lang language.Tag, key string, args ...any) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
+
+// Localize translates message of key to language stored in ctx
+func Localize(ctx context.Context, key string, args ...any) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+type ctxKey struct{}
+
+// WithLanguage returns copy of ctx with lang
+func WithLanguage(ctx context.Context, lang language.Tag) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// FromContext returns language stored in ctx or Default if there is none
+func FromContext(ctx context.Context) language.Tag {
+	if lang, ok := ctx.Value(ctxKey{}).(language.Tag); ok {
+		return lang
+	}
+
+	return Default
+}
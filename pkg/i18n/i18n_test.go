@@ -0,0 +1,46 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, messages := range catalogs {
+		for key := range catalogs[Default] {
+			assert.Contains(t, messages, key, "%s catalog misses %q", lang, key)
+		}
+		for key := range messages {
+			assert.Contains(t, catalogs[Default], key, "%s catalog has unknown %q", lang, key)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           language.Tag
+	}{
+		{acceptLanguage: "", want: language.English},
+		{acceptLanguage: "ru", want: language.Russian},
+		{acceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8", want: language.Russian},
+		{acceptLanguage: "de-DE,en;q=0.5", want: language.English},
+		{acceptLanguage: "ja", want: language.English},
+		{acceptLanguage: "not a language", want: language.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "invalid credentials", Translate(language.English, "invalid_credentials"))
+	assert.Equal(t, "неверный email или пароль", Translate(language.Russian, "invalid_credentials"))
+	assert.Equal(t, "invalid credentials", Translate(language.Japanese, "invalid_credentials"))
+	assert.Equal(t, "unknown_key", Translate(language.Russian, "unknown_key"))
+}
@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/natefinch/lumberjack"
 )
@@ -13,42 +16,177 @@ const (
 	envProd  = "prod"
 )
 
-func InitLogger(env string) *slog.Logger {
-	var log *slog.Logger
-	switch env {
-	case envLocal:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-				Level:     slog.LevelDebug,
-				AddSource: true,
-			}),
-		)
-	case envDev:
-		lumber := &lumberjack.Logger{
-			Filename:   "logs/app.log",
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+)
+
+// Options configures logger created by New
+//
+// Zero fields are filled with defaults of the env, see DefaultOptions
+type Options struct {
+	Level     string
+	Format    string
+	Output    string
+	AddSource *bool
+	File      FileOptions
+	Syslog    SyslogOptions
+	// RedactKeys are attribute keys redacted in addition to DefaultRedactKeys
+	RedactKeys []string
+}
+
+// FileOptions configures rotation of log file, sizes are in megabytes and age is in days
+type FileOptions struct {
+	Path       string
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	Compress   bool
+}
+
+// SyslogOptions configures syslog connection, local syslog daemon is used if Network and Address are empty
+type SyslogOptions struct {
+	Network string
+	Address string
+	Tag     string
+}
+
+// DefaultOptions returns logger options of given env
+//
+// local writes debug logs to stdout, dev and prod write to rotated logs/app.log file
+// on debug and info levels respectively, unknown envs write info logs to stdout
+func DefaultOptions(env string) Options {
+	addSource := true
+	opts := Options{
+		Level:     slog.LevelInfo.String(),
+		Format:    FormatJSON,
+		Output:    OutputStdout,
+		AddSource: &addSource,
+		File: FileOptions{
+			Path:       "logs/app.log",
 			MaxSize:    10,
 			MaxBackups: 3,
 			MaxAge:     7,
-		}
-		log = slog.New(
-			slog.NewJSONHandler(lumber, &slog.HandlerOptions{
-				Level:     slog.LevelDebug,
-				AddSource: true,
-			}),
-		)
+		},
+		Syslog: SyslogOptions{
+			Tag: "sso",
+		},
+	}
+
+	switch env {
+	case envLocal:
+		opts.Level = slog.LevelDebug.String()
+	case envDev:
+		opts.Level = slog.LevelDebug.String()
+		opts.Output = OutputFile
 	case envProd:
-		lumber := &lumberjack.Logger{
-			Filename:   "logs/app.log",
-			MaxSize:    10,
-			MaxBackups: 3,
-			MaxAge:     7,
-		}
-		log = slog.New(
-			slog.NewJSONHandler(lumber, &slog.HandlerOptions{
-				Level:     slog.LevelInfo,
-				AddSource: true,
-			}),
-		)
-	}
-	return log
+		opts.Output = OutputFile
+	}
+
+	return opts
+}
+
+// New creates logger for given env overriding its default options with non-zero fields of opts
+//
+// Every logger redacts secrets and masks emails, see RedactHandler.
+// Returned level var can be used to change level of the logger at runtime
+func New(env string, opts Options) (*slog.Logger, *slog.LevelVar, error) {
+	opts = mergeOptions(DefaultOptions(env), opts)
+
+	level := &slog.LevelVar{}
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
+	}
+
+	out, err := newOutput(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: *opts.AddSource,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(out, handlerOpts)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	keys := append(append([]string{}, DefaultRedactKeys...), opts.RedactKeys...)
+
+	return slog.New(NewRedactHandler(handler, keys...)), level, nil
+}
+
+func newOutput(opts Options) (io.Writer, error) {
+	switch strings.ToLower(opts.Output) {
+	case OutputStdout:
+		return os.Stdout, nil
+	case OutputStderr:
+		return os.Stderr, nil
+	case OutputFile:
+		return &lumberjack.Logger{
+			Filename:   opts.File.Path,
+			MaxSize:    opts.File.MaxSize,
+			MaxBackups: opts.File.MaxBackups,
+			MaxAge:     opts.File.MaxAge,
+			Compress:   opts.File.Compress,
+		}, nil
+	case OutputSyslog:
+		return newSyslogWriter(opts.Syslog)
+	default:
+		return nil, fmt.Errorf("unknown log output %q", opts.Output)
+	}
+}
+
+func mergeOptions(defaults Options, opts Options) Options {
+	if opts.Level != "" {
+		defaults.Level = opts.Level
+	}
+	if opts.Format != "" {
+		defaults.Format = opts.Format
+	}
+	if opts.Output != "" {
+		defaults.Output = opts.Output
+	}
+	if opts.AddSource != nil {
+		defaults.AddSource = opts.AddSource
+	}
+	if opts.File.Path != "" {
+		defaults.File.Path = opts.File.Path
+	}
+	if opts.File.MaxSize != 0 {
+		defaults.File.MaxSize = opts.File.MaxSize
+	}
+	if opts.File.MaxBackups != 0 {
+		defaults.File.MaxBackups = opts.File.MaxBackups
+	}
+	if opts.File.MaxAge != 0 {
+		defaults.File.MaxAge = opts.File.MaxAge
+	}
+	defaults.File.Compress = defaults.File.Compress || opts.File.Compress
+	if opts.Syslog.Network != "" {
+		defaults.Syslog.Network = opts.Syslog.Network
+	}
+	if opts.Syslog.Address != "" {
+		defaults.Syslog.Address = opts.Syslog.Address
+	}
+	if opts.Syslog.Tag != "" {
+		defaults.Syslog.Tag = opts.Syslog.Tag
+	}
+	defaults.RedactKeys = opts.RedactKeys
+
+	return defaults
 }
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_EnvDefaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		expectedLevel slog.Level
+	}{
+		{
+			name:          "local",
+			env:           envLocal,
+			expectedLevel: slog.LevelDebug,
+		},
+		{
+			name:          "unknown env",
+			env:           "staging",
+			expectedLevel: slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, level, err := New(tt.env, Options{})
+			require.NoError(t, err)
+			require.NotNil(t, log)
+
+			assert.Equal(t, tt.expectedLevel, level.Level())
+		})
+	}
+}
+
+func TestNew_Overrides(t *testing.T) {
+	log, level, err := New(envLocal, Options{
+		Level:  "warn",
+		Format: FormatText,
+		Output: OutputStderr,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, slog.LevelWarn, level.Level())
+	assert.False(t, log.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "invalid level",
+			opts: Options{Level: "verbose"},
+		},
+		{
+			name: "invalid format",
+			opts: Options{Format: "xml"},
+		},
+		{
+			name: "invalid output",
+			opts: Options{Output: "kafka"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := New(envLocal, tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_LevelChangesAtRuntime(t *testing.T) {
+	log, level, err := New(envProd, Options{Output: OutputStdout})
+	require.NoError(t, err)
+
+	assert.False(t, log.Enabled(context.Background(), slog.LevelDebug))
+
+	level.Set(slog.LevelDebug)
+
+	assert.True(t, log.Enabled(context.Background(), slog.LevelDebug))
+}
@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactKeys are attribute keys whose values never reach log output
+var DefaultRedactKeys = []string{
+	"secret",
+	"password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"authorization",
+}
+
+var emailRe = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// RedactHandler is a slog.Handler wrapper which replaces values of sensitive attributes
+// with a placeholder and masks emails in messages and string values.
+// Attributes of groups and fields of structs and maps logged with slog.Any are redacted too
+type RedactHandler struct {
+	handler slog.Handler
+	keys    []string
+}
+
+// NewRedactHandler wraps handler redacting attributes with given keys
+//
+// Keys are matched ignoring case, underscores and dashes, and as suffixes of attribute keys,
+// so "refresh_token" key redacts "refreshToken" and "secret" key redacts "client_secret".
+// If no keys given, DefaultRedactKeys are used
+func NewRedactHandler(handler slog.Handler, keys ...string) *RedactHandler {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+
+	set := make([]string, 0, len(keys))
+	for _, key := range keys {
+		set = append(set, normalizeKey(key))
+	}
+
+	return &RedactHandler{
+		handler: handler,
+		keys:    set,
+	}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redactedRecord := slog.NewRecord(r.Time, r.Level, MaskEmails(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redactedRecord.AddAttrs(h.redact(a))
+		return true
+	})
+
+	return h.handler.Handle(ctx, redactedRecord)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redactedAttrs = append(redactedAttrs, h.redact(a))
+	}
+
+	return &RedactHandler{
+		handler: h.handler.WithAttrs(redactedAttrs),
+		keys:    h.keys,
+	}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{
+		handler: h.handler.WithGroup(name),
+		keys:    h.keys,
+	}
+}
+
+func (h *RedactHandler) redact(a slog.Attr) slog.Attr {
+	if h.sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, MaskEmails(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redactedGroup := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			redactedGroup = append(redactedGroup, h.redact(ga))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redactedGroup...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+		return slog.Attr{Key: a.Key, Value: h.redactAny(reflect.ValueOf(v.Any()))}
+	}
+
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// sensitive reports whether attribute with key must be redacted
+func (h *RedactHandler) sensitive(key string) bool {
+	key = normalizeKey(key)
+	return slices.ContainsFunc(h.keys, func(k string) bool { return strings.HasSuffix(key, k) })
+}
+
+// redactAny converts structs and maps with string keys into groups of redacted attributes,
+// slices of them into lists of such groups. Other values and values marshaling themselves are kept
+func (h *RedactHandler) redactAny(rv reflect.Value) slog.Value {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || !rv.CanInterface() {
+		return slog.AnyValue(nil)
+	}
+
+	switch rv.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler, fmt.Stringer:
+		return slog.AnyValue(rv.Interface())
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		t := rv.Type()
+		attrs := make([]slog.Attr, 0, t.NumField())
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			attrs = append(attrs, h.redact(slog.Any(name, rv.Field(i).Interface())))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			attrs = append(attrs, h.redact(slog.Any(key.String(), rv.MapIndex(key).Interface())))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if elem := rv.Type().Elem().Kind(); elem != reflect.Struct && elem != reflect.Map && elem != reflect.Pointer && elem != reflect.Interface {
+			break
+		}
+		values := make([]any, 0, rv.Len())
+		for i := range rv.Len() {
+			values = append(values, plain(h.redactAny(rv.Index(i))))
+		}
+		return slog.AnyValue(values)
+	}
+
+	return slog.AnyValue(rv.Interface())
+}
+
+// plain converts group value into a map of its attributes, so it can be an element of a list
+func plain(v slog.Value) any {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+
+	m := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		m[a.Key] = plain(a.Value.Resolve())
+	}
+
+	return m
+}
+
+// jsonFieldName returns name of struct field in JSON, reports false if field isn't marshaled
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+
+	return name, true
+}
+
+// normalizeKey lowercases key and strips underscores and dashes from it
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// MaskEmails replaces local part of every email in s except its first character,
+// e.g. "john.doe@example.com" becomes "j***@example.com"
+func MaskEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+
+	return emailRe.ReplaceAllString(s, "$1***@$2")
+}
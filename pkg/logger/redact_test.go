@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret   = "my_secret_app1_key_for_tests"
+	testPassword = "P@ssw0rd!"
+	testEmail    = "john.doe@example.com"
+	maskedEmail  = "j***@example.com"
+)
+
+type credentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string
+	Refresh      string `json:"refreshToken"`
+	Nested       *credentials
+	Attributes   map[string]string
+	Ignored      string `json:"-"`
+}
+
+type secretValuer struct{}
+
+func (secretValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("secret", testSecret), slog.String("email", testEmail))
+}
+
+func newTestLogger(buf *bytes.Buffer, keys ...string) *slog.Logger {
+	return slog.New(NewRedactHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}), keys...))
+}
+
+func TestRedactHandler_SecretsNeverReachOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(log *slog.Logger)
+	}{
+		{
+			name: "plain attribute",
+			log: func(log *slog.Logger) {
+				log.Debug("secret", slog.String("secret", testSecret))
+			},
+		},
+		{
+			name: "key in different case",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.String("Password", testPassword), slog.String("SECRET", testSecret))
+			},
+		},
+		{
+			name: "non string value",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("token", []byte(testSecret)))
+			},
+		},
+		{
+			name: "attribute in group",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Group("request", slog.String("password", testPassword)))
+			},
+		},
+		{
+			name: "logger with attributes",
+			log: func(log *slog.Logger) {
+				log.With(slog.String("secret", testSecret)).WithGroup("req").Info("login")
+			},
+		},
+		{
+			name: "camel case key",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.String("refreshToken", testSecret), slog.String("accessToken", testSecret))
+			},
+		},
+		{
+			name: "key with sensitive suffix",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.String("clientSecret", testSecret), slog.String("new-password", testPassword))
+			},
+		},
+		{
+			name: "camel case key in nested group",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Group("oauth", slog.Group("client", slog.String("clientSecret", testSecret))))
+			},
+		},
+		{
+			name: "attribute of logger group",
+			log: func(log *slog.Logger) {
+				log.WithGroup("oauth").With(slog.String("token", testSecret)).Info("login", slog.String("Refresh-Token", testSecret))
+			},
+		},
+		{
+			name: "struct",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("client", credentials{ClientID: "client", ClientSecret: testSecret, Refresh: testSecret}))
+			},
+		},
+		{
+			name: "pointer to nested struct",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("client", &credentials{
+					Nested:     &credentials{ClientSecret: testSecret},
+					Attributes: map[string]string{"password": testPassword, "email": testEmail},
+				}))
+			},
+		},
+		{
+			name: "map",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("form", map[string]any{"client_secret": testSecret, "user": map[string]string{"password": testPassword}}))
+			},
+		},
+		{
+			name: "slice of structs",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("clients", []credentials{{ClientSecret: testSecret}, {Refresh: testSecret}}))
+			},
+		},
+		{
+			name: "log valuer",
+			log: func(log *slog.Logger) {
+				log.Info("login", slog.Any("app", secretValuer{}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(newTestLogger(&buf))
+
+			assert.NotEmpty(t, buf.String())
+			assert.NotContains(t, buf.String(), testSecret)
+			assert.NotContains(t, buf.String(), testPassword)
+			assert.NotContains(t, buf.String(), testEmail)
+		})
+	}
+}
+
+func TestRedactHandler_MasksEmails(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(log *slog.Logger)
+	}{
+		{
+			name: "message",
+			log: func(log *slog.Logger) {
+				log.Info("failed to login " + testEmail)
+			},
+		},
+		{
+			name: "string attribute",
+			log: func(log *slog.Logger) {
+				log.Error("failed to login", slog.String("email", testEmail))
+			},
+		},
+		{
+			name: "error attribute",
+			log: func(log *slog.Logger) {
+				log.Error("failed to login", slog.Any("error", errors.New("user "+testEmail+" not found")))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(newTestLogger(&buf))
+
+			assert.NotContains(t, buf.String(), testEmail)
+			assert.Contains(t, buf.String(), maskedEmail)
+		})
+	}
+}
+
+func TestRedactHandler_KeepsOtherValues(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(&buf)
+
+	log.Info("login",
+		slog.String("tokenType", "Bearer"),
+		slog.Any("client", credentials{ClientID: "client", ClientSecret: testSecret, Ignored: "ignored"}),
+		slog.Any("scopes", []string{"openid", "profile"}),
+	)
+
+	assert.Contains(t, buf.String(), `"tokenType":"Bearer"`)
+	assert.Contains(t, buf.String(), `"client_id":"client"`)
+	assert.Contains(t, buf.String(), `"ClientSecret":"[REDACTED]"`)
+	assert.NotContains(t, buf.String(), "ignored")
+	assert.Contains(t, buf.String(), `"scopes":["openid","profile"]`)
+}
+
+func TestRedactHandler_CustomKeys(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(&buf, "api_key")
+
+	log.Info("request", slog.String("api_key", testSecret), slog.String("user", "admin"))
+
+	assert.NotContains(t, buf.String(), testSecret)
+	assert.Contains(t, buf.String(), `"user":"admin"`)
+}
+
+func TestRedactHandler_Enabled(t *testing.T) {
+	h := NewRedactHandler(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestNew_RedactsSecrets(t *testing.T) {
+	log, _, err := New(envLocal, Options{})
+	require.NoError(t, err)
+
+	_, ok := log.Handler().(*RedactHandler)
+	assert.True(t, ok)
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+func newSyslogWriter(opts SyslogOptions) (io.Writer, error) {
+	w, err := syslog.Dial(opts.Network, opts.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, opts.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	return w, nil
+}
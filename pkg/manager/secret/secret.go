@@ -2,17 +2,71 @@ package secret
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
-	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type Manager struct{}
+var tracer = otel.Tracer("github.com/4aykovski/grpc_auth_sso/pkg/manager/secret")
+
+// secretSize is a size of generated client secrets in bytes
+const secretSize = 32
+
+type store interface {
+	// GetAppSecretHash returns hash of client secret of app, empty if app has no secret
+	GetAppSecretHash(ctx context.Context, appID int) (string, error)
+}
+
+// Manager generates client secrets of apps and checks them against stored hashes
+//
+// Secrets are used only to authenticate OAuth clients and are never stored in plain or reversible form.
+// Generated secrets carry 256 bits of entropy, so SHA-256 is enough to protect them at rest
+type Manager struct {
+	store store
+}
+
+// New creates Manager checking secrets against hashes in store
+func New(store store) *Manager {
+	return &Manager{store: store}
+}
+
+// CheckSecret compares secret with client secret of app in constant time
+//
+// Returns false if app has no client secret
+func (m *Manager) CheckSecret(ctx context.Context, appID int, secret string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "secret.CheckSecret")
+	defer span.End()
+	span.SetAttributes(attribute.Int("app.id", appID))
+
+	hash, err := m.store.GetAppSecretHash(ctx, appID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get app secret: %w", err)
+	}
+	if hash == "" || secret == "" {
+		return false, nil
+	}
+
+	return subtle.ConstantTimeCompare([]byte(Hash(secret)), []byte(hash)) == 1, nil
+}
 
-func (m *Manager) GetSecret(ctx context.Context, appID int) (string, error) {
-	secret := os.Getenv(fmt.Sprintf("APP%d_SECRET", appID))
-	if secret == "" {
-		return "", fmt.Errorf("secret not found")
+// GenerateSecret returns new random client secret and its hash to store
+func (m *Manager) GenerateSecret() (secret, hash string, err error) {
+	raw := make([]byte, secretSize)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
 	}
+	secret = hex.EncodeToString(raw)
+
+	return secret, Hash(secret), nil
+}
 
-	return secret, nil
+// Hash returns hex encoded SHA-256 hash of client secret
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
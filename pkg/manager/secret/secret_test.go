@@ -0,0 +1,57 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore map[int]string
+
+func (s fakeStore) GetAppSecretHash(_ context.Context, appID int) (string, error) {
+	hash, ok := s[appID]
+	if !ok {
+		return "", errors.New("app not found")
+	}
+
+	return hash, nil
+}
+
+func TestManager_CheckSecret(t *testing.T) {
+	m := New(nil)
+
+	secret, hash, err := m.GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 2*secretSize)
+	assert.NotEqual(t, secret, hash)
+	assert.Equal(t, Hash(secret), hash)
+
+	m = New(fakeStore{1: hash, 2: ""})
+
+	tests := []struct {
+		name   string
+		appID  int
+		secret string
+		ok     bool
+	}{
+		{name: "matching secret", appID: 1, secret: secret, ok: true},
+		{name: "another secret", appID: 1, secret: secret + "x", ok: false},
+		{name: "hash instead of secret", appID: 1, secret: hash, ok: false},
+		{name: "empty secret", appID: 1, secret: "", ok: false},
+		{name: "app without secret", appID: 2, secret: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := m.CheckSecret(context.Background(), tt.appID, tt.secret)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+
+	_, err = m.CheckSecret(context.Background(), 3, secret)
+	assert.Error(t, err)
+}
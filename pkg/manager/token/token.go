@@ -2,33 +2,288 @@ package token
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/4aykovski/grpc_auth_sso/internal/entity"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type Manager struct{}
+const refreshTokenSize = 32
 
+// Manager signs access tokens with private key of the service and verifies them with its public key
+//
+// Keys are never derived from secrets of apps, so neither clients nor apps can forge tokens
+type Manager struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
+	// kid is a key id set in headers of signed tokens, it is a JWK thumbprint of the public key (RFC 7638)
+	kid string
+	jwk JWK
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS is a set of public keys tokens are verified with
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// New creates Manager signing tokens with key
+//
+// key must be ECDSA P-256 (ES256) or Ed25519 (EdDSA) private key
+func New(key crypto.Signer) (*Manager, error) {
+	m := &Manager{key: key}
+
+	switch k := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curve of ECDSA keys is supported")
+		}
+
+		size := (k.Curve.Params().BitSize + 7) / 8
+		m.method = jwt.SigningMethodES256
+		m.jwk = JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}
+	case ed25519.PublicKey:
+		m.method = jwt.SigningMethodEdDSA
+		m.jwk = JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", k)
+	}
+
+	m.kid = thumbprint(m.jwk)
+	m.jwk.Kid = m.kid
+	m.jwk.Use = "sig"
+	m.jwk.Alg = m.method.Alg()
+
+	return m, nil
+}
+
+// Load creates Manager signing tokens with PEM encoded PKCS #8 private key read from file at path
+func Load(path string) (*Manager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("token signing key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token signing key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+
+	return New(signer)
+}
+
+// PublicKey returns public key tokens are verified with
+func (m *Manager) PublicKey() crypto.PublicKey {
+	return m.key.Public()
+}
+
+// JWKS returns public key set of the service, it is published so other services can verify tokens
+func (m *Manager) JWKS() JWKS {
+	return JWKS{Keys: []JWK{m.jwk}}
+}
+
+// GenerateJWTToken generates access token of user in session of the app,
+// the token is valid only while the session is active
 func (m *Manager) GenerateJWTToken(
 	ctx context.Context,
 	user entity.User,
 	app entity.App,
+	sessionID int64,
+	roles []entity.Role,
 	tokenTTL time.Duration,
-	secret string,
 ) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+	token := m.newToken()
 
 	claims := token.Claims.(jwt.MapClaims)
 	claims["user_id"] = user.ID
 	claims["email"] = user.Email
 	claims["app_id"] = app.ID
+	claims["sid"] = sessionID
 	claims["exp"] = time.Now().Add(tokenTTL).Unix()
+	if len(roles) > 0 {
+		claims["roles"], claims["permissions"] = roleClaims(roles)
+	}
 
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := token.SignedString(m.key)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+// GenerateClientToken generates access token of client credentials grant, its subject is the app
+// identified by client_id claim and it carries no user claims
+func (m *Manager) GenerateClientToken(
+	ctx context.Context,
+	app entity.App,
+	scopes []string,
+	tokenTTL time.Duration,
+) (string, error) {
+	token := m.newToken()
+
+	claims := token.Claims.(jwt.MapClaims)
+	claims["sub"] = app.ClientID
+	claims["client_id"] = app.ClientID
+	claims["app_id"] = app.ID
+	claims["exp"] = time.Now().Add(tokenTTL).Unix()
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
+	tokenString, err := token.SignedString(m.key)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ParseJWTToken verifies access token with public key of the service and returns its principal
+//
+// Client credentials tokens have no user, they are returned as app principals with client id and scopes
+func (m *Manager) ParseJWTToken(ctx context.Context, tokenString string) (entity.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		if kid, ok := token.Header["kid"]; ok && kid != m.kid {
+			return nil, errors.New("unknown signing key")
+		}
+
+		return m.key.Public(), nil
+	}, jwt.WithValidMethods([]string{m.method.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return entity.Principal{}, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	appID, _ := claims["app_id"].(float64)
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		clientID, _ := claims["client_id"].(string)
+		if clientID == "" {
+			return entity.Principal{}, errors.New("user_id and client_id claims are missing")
+		}
+
+		scope, _ := claims["scope"].(string)
+
+		return entity.Principal{
+			AppID:    int(appID),
+			ClientID: clientID,
+			Scopes:   strings.Fields(scope),
+		}, nil
+	}
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return entity.Principal{}, errors.New("sid claim is missing")
+	}
+	email, _ := claims["email"].(string)
+
+	return entity.Principal{
+		UserID:      int64(userID),
+		Email:       email,
+		AppID:       int(appID),
+		SessionID:   int64(sessionID),
+		Roles:       stringsClaim(claims["roles"]),
+		Permissions: stringsClaim(claims["permissions"]),
+	}, nil
+}
+
+// GenerateRefreshToken generates opaque random refresh token
+func (m *Manager) GenerateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (m *Manager) newToken() *jwt.Token {
+	token := jwt.New(m.method)
+	token.Header["kid"] = m.kid
+
+	return token
+}
+
+// thumbprint returns JWK thumbprint of public key (RFC 7638)
+func thumbprint(jwk JWK) string {
+	// required members of key type in lexicographic order, values are base64url or names and need no escaping
+	canonical := fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s"}`, jwk.Crv, jwk.Kty, jwk.X)
+	if jwk.Kty == "EC" {
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s","y":"%s"}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// roleClaims returns names of roles and sorted unique permissions they grant
+func roleClaims(roles []entity.Role) ([]string, []string) {
+	names := make([]string, 0, len(roles))
+	var permissions []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+		permissions = append(permissions, role.Permissions...)
+	}
+
+	slices.Sort(permissions)
+
+	return names, slices.Compact(permissions)
+}
+
+func stringsClaim(claim any) []string {
+	values, _ := claim.([]any)
+
+	var s []string
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			s = append(s, str)
+		}
+	}
+
+	return s
+}
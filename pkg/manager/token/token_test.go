@@ -0,0 +1,183 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newECDSAKey(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestManager_RoundTrip(t *testing.T) {
+	for name, key := range map[string]crypto.Signer{"EdDSA": newEd25519Key(t), "ES256": newECDSAKey(t)} {
+		t.Run(name, func(t *testing.T) {
+			m, err := New(key)
+			require.NoError(t, err)
+
+			user := entity.User{ID: 7, Email: "user@example.com"}
+			app := entity.App{ID: 1}
+			roles := []entity.Role{{Name: "editor", Permissions: []string{"apps:edit", "apps:read"}}}
+
+			token, err := m.GenerateJWTToken(context.Background(), user, app, 42, roles, time.Minute)
+			require.NoError(t, err)
+
+			principal, err := m.ParseJWTToken(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, entity.Principal{
+				UserID:      7,
+				Email:       "user@example.com",
+				AppID:       1,
+				SessionID:   42,
+				Roles:       []string{"editor"},
+				Permissions: []string{"apps:edit", "apps:read"},
+			}, principal)
+		})
+	}
+}
+
+func TestManager_ClientToken(t *testing.T) {
+	m, err := New(newEd25519Key(t))
+	require.NoError(t, err)
+
+	app := entity.App{ID: 3, ClientID: "client"}
+
+	token, err := m.GenerateClientToken(context.Background(), app, []string{"orders:read", "sso:authorize"}, time.Minute)
+	require.NoError(t, err)
+
+	principal, err := m.ParseJWTToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Principal{
+		AppID:    3,
+		ClientID: "client",
+		Scopes:   []string{"orders:read", "sso:authorize"},
+	}, principal)
+	assert.True(t, principal.IsApp())
+
+	// token without user and client is rejected
+	anonymous := m.newToken()
+	anonymous.Claims = jwt.MapClaims{"app_id": 3, "exp": time.Now().Add(time.Minute).Unix()}
+	tokenString, err := anonymous.SignedString(m.key)
+	require.NoError(t, err)
+
+	_, err = m.ParseJWTToken(context.Background(), tokenString)
+	assert.Error(t, err)
+}
+
+func TestManager_RejectsForgedTokens(t *testing.T) {
+	m, err := New(newEd25519Key(t))
+	require.NoError(t, err)
+
+	other, err := New(newEd25519Key(t))
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"user_id": 1, "app_id": 1, "sid": 1, "exp": time.Now().Add(time.Minute).Unix()}
+
+	// token signed with a shared secret, e.g. client secret of an app
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("client-secret"))
+	require.NoError(t, err)
+
+	// token signed with another key, but claiming the key id of the service
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	forged.Header["kid"] = m.kid
+	otherToken, err := forged.SignedString(other.key)
+	require.NoError(t, err)
+
+	expired, err := m.GenerateJWTToken(context.Background(), entity.User{ID: 1}, entity.App{ID: 1}, 1, nil, -time.Minute)
+	require.NoError(t, err)
+
+	// user token without session can't be revoked
+	withoutSession := m.newToken()
+	withoutSession.Claims = jwt.MapClaims{"user_id": 1, "app_id": 1, "exp": time.Now().Add(time.Minute).Unix()}
+	withoutSessionToken, err := withoutSession.SignedString(m.key)
+	require.NoError(t, err)
+
+	tokens := map[string]string{
+		"hmac":            hmacToken,
+		"other key":       otherToken,
+		"expired":         expired,
+		"without session": withoutSessionToken,
+		"malformed":       "token",
+	}
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.ParseJWTToken(context.Background(), token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestManager_JWKS(t *testing.T) {
+	m, err := New(newECDSAKey(t))
+	require.NoError(t, err)
+
+	jwks := m.JWKS()
+	require.Len(t, jwks.Keys, 1)
+
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "P-256", jwk.Crv)
+	assert.Equal(t, "ES256", jwk.Alg)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.NotEmpty(t, jwk.X)
+	assert.NotEmpty(t, jwk.Y)
+
+	token, err := m.GenerateClientToken(context.Background(), entity.App{ID: 1, ClientID: "client"}, nil, time.Minute)
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, jwk.Kid, parsed.Header["kid"])
+}
+
+func TestThumbprint(t *testing.T) {
+	// example of RFC 8037 appendix A.3
+	jwk := JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint(jwk))
+}
+
+func TestLoad(t *testing.T) {
+	key := newEd25519Key(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.Public(), m.PublicKey())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package principal
+
+import (
+	"context"
+
+	"github.com/4aykovski/grpc_auth_sso/internal/entity"
+)
+
+type ctxKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying authenticated principal
+func WithPrincipal(ctx context.Context, p entity.Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns principal stored in ctx
+//
+// If request wasn't authenticated, returns false
+func FromContext(ctx context.Context) (entity.Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(entity.Principal)
+	return p, ok
+}
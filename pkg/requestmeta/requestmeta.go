@@ -0,0 +1,27 @@
+package requestmeta
+
+import "context"
+
+type ctxKey struct{}
+
+// Meta describes the client that issued the current request
+type Meta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	// ClientIdentity is an identity of verified client certificate, empty if client didn't present one
+	ClientIdentity string
+}
+
+// WithMeta returns a copy of ctx carrying meta
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, ctxKey{}, meta)
+}
+
+// FromContext returns request meta stored in ctx
+//
+// If ctx doesn't carry meta, returns zero Meta
+func FromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(ctxKey{}).(Meta)
+	return meta
+}
@@ -0,0 +1,153 @@
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkInterval limits how often files are checked for changes
+const checkInterval = time.Second
+
+// alpnH2 is ALPN protocol id of HTTP/2
+const alpnH2 = "h2"
+
+// Reloader serves server certificate and client CA bundle read from files
+// and rereads them when the files change, so certificates can be rotated without restart
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// New creates Reloader and reads files for the first time
+//
+// clientCAFile is optional, client certificates aren't requested if it is empty
+func New(certFile string, keyFile string, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns server TLS config using current certificate and client CA bundle on every handshake
+//
+// If client CA bundle is configured, client certificates are verified against it
+// and are required if requireClientCert is true. HTTP/2 is negotiated with ALPN as gRPC clients require it
+func (r *Reloader) TLSConfig(requireClientCert bool) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{alpnH2},
+	}
+
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs := r.current()
+
+		handshakeCfg := base.Clone()
+		handshakeCfg.Certificates = []tls.Certificate{*cert}
+		if clientCAs != nil {
+			handshakeCfg.ClientCAs = clientCAs
+			handshakeCfg.ClientAuth = tls.VerifyClientCertIfGiven
+			if requireClientCert {
+				handshakeCfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+		}
+
+		return handshakeCfg, nil
+	}
+
+	return cfg
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= checkInterval {
+		r.checkedAt = time.Now()
+
+		if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+			// keep serving previous certificate if new one is broken or written partially
+			_ = r.loadLocked()
+		}
+	}
+
+	return r.cert, r.clientCAs
+}
+
+func (r *Reloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loadLocked()
+}
+
+func (r *Reloader) loadLocked() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("failed to parse client CA bundle")
+		}
+	}
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+
+	return nil
+}
+
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,167 @@
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCert(t *testing.T, dir string, commonName string, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	return certFile, keyFile
+}
+
+func servedCommonName(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+
+	clientCfg, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Len(t, clientCfg.Certificates, 1)
+
+	leaf, err := x509.ParseCertificate(clientCfg.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader_ReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	certFile, keyFile := writeCert(t, dir, "old", now.Add(-time.Minute))
+
+	r, err := New(certFile, keyFile, "")
+	require.NoError(t, err)
+	cfg := r.TLSConfig(false)
+
+	assert.Equal(t, "old", servedCommonName(t, cfg))
+
+	writeCert(t, dir, "new", now)
+	r.checkedAt = time.Time{}
+
+	assert.Equal(t, "new", servedCommonName(t, cfg))
+}
+
+func TestReloader_KeepsCertificateIfNewIsBroken(t *testing.T) {
+	dir := t.TempDir()
+
+	certFile, keyFile := writeCert(t, dir, "old", time.Now().Add(-time.Minute))
+
+	r, err := New(certFile, keyFile, "")
+	require.NoError(t, err)
+	cfg := r.TLSConfig(false)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	r.checkedAt = time.Time{}
+
+	assert.Equal(t, "old", servedCommonName(t, cfg))
+}
+
+func TestReloader_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	certFile, keyFile := writeCert(t, dir, "ca", time.Now())
+
+	tests := []struct {
+		name              string
+		clientCAFile      string
+		requireClientCert bool
+		expected          tls.ClientAuthType
+	}{
+		{
+			name:     "no client CA",
+			expected: tls.NoClientCert,
+		},
+		{
+			name:         "optional client certificate",
+			clientCAFile: certFile,
+			expected:     tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:              "required client certificate",
+			clientCAFile:      certFile,
+			requireClientCert: true,
+			expected:          tls.RequireAndVerifyClientCert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(certFile, keyFile, tt.clientCAFile)
+			require.NoError(t, err)
+
+			clientCfg, err := r.TLSConfig(tt.requireClientCert).GetConfigForClient(&tls.ClientHelloInfo{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, clientCfg.ClientAuth)
+		})
+	}
+}
+
+func TestNew_MissingFiles(t *testing.T) {
+	_, err := New("missing.crt", "missing.key", "")
+	assert.Error(t, err)
+}
+
+func TestReloader_NegotiatesHTTP2(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir(), "server", time.Now())
+
+	r, err := New(certFile, keyFile, "")
+	require.NoError(t, err)
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+	})
+
+	server := tls.Server(serverConn, r.TLSConfig(false))
+	client := tls.Client(clientConn, &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2"},
+	})
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Handshake() }()
+
+	require.NoError(t, client.Handshake())
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, "h2", client.ConnectionState().NegotiatedProtocol)
+	assert.Equal(t, "h2", server.ConnectionState().NegotiatedProtocol)
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminRoles_RequireToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AdminClient.GrantAdmin(ctx, &ssov1.GrantAdminRequest{UserId: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = withToken(ctx, "not-a-token")
+
+	_, err = st.AdminClient.RevokeAdmin(ctx, &ssov1.RevokeAdminRequest{UserId: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAdminRoles_NonAdminIsDenied(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	_, err := st.AdminClient.GrantAdmin(ctx, &ssov1.GrantAdminRequest{UserId: userID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.GrantRole(ctx, &ssov1.GrantRoleRequest{UserId: userID, Role: "admin"})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAdminRoles_GrantRevokeAdmin(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminsMu.Lock()
+	defer adminsMu.Unlock()
+
+	_, adminToken := adminLogin(ctx, t, st)
+	userID, _ := newUser(ctx, t, st)
+	ctx = withToken(ctx, adminToken)
+
+	grantResp, err := st.AdminClient.GrantAdmin(ctx, &ssov1.GrantAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.True(t, grantResp.GetGranted())
+
+	// granting again is a no-op
+	grantResp, err = st.AdminClient.GrantAdmin(ctx, &ssov1.GrantAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.False(t, grantResp.GetGranted())
+
+	isAdminResp, err := st.AuthClient.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.True(t, isAdminResp.GetIsAdmin())
+
+	revokeResp, err := st.AdminClient.RevokeAdmin(ctx, &ssov1.RevokeAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.True(t, revokeResp.GetRevoked())
+
+	revokeResp, err = st.AdminClient.RevokeAdmin(ctx, &ssov1.RevokeAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.False(t, revokeResp.GetRevoked())
+
+	isAdminResp, err = st.AuthClient.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.False(t, isAdminResp.GetIsAdmin())
+}
+
+func TestAdminRoles_GrantRevokeAppRole(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, adminToken := adminLogin(ctx, t, st)
+	userID, _ := newUser(ctx, t, st)
+	ctx = withToken(ctx, adminToken)
+
+	grantResp, err := st.AdminClient.GrantRole(ctx, &ssov1.GrantRoleRequest{UserId: userID, Role: "admin", AppId: appID})
+	require.NoError(t, err)
+	assert.True(t, grantResp.GetGranted())
+
+	// role granted in an app doesn't make user a global admin
+	isAdminResp, err := st.AuthClient.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.False(t, isAdminResp.GetIsAdmin())
+
+	revokeResp, err := st.AdminClient.RevokeRole(ctx, &ssov1.RevokeRoleRequest{UserId: userID, Role: "admin", AppId: appID})
+	require.NoError(t, err)
+	assert.True(t, revokeResp.GetRevoked())
+}
+
+func TestAdminRoles_LastAdminIsKept(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminsMu.Lock()
+	defer adminsMu.Unlock()
+
+	adminID, adminToken := adminLogin(ctx, t, st)
+	ctx = withToken(ctx, adminToken)
+
+	_, err := st.AdminClient.RevokeAdmin(ctx, &ssov1.RevokeAdminRequest{UserId: adminID})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, apierror.ReasonLastAdmin, errorReason(status.Convert(err)))
+
+	isAdminResp, err := st.AuthClient.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: adminID})
+	require.NoError(t, err)
+	assert.True(t, isAdminResp.GetIsAdmin())
+}
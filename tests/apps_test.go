@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestApps_RequirePermission(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	_, err := st.AdminClient.CreateApp(ctx, &ssov1.CreateAppRequest{
+		Name:         "tests-app",
+		RedirectUris: []string{"https://example.com/callback"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.ListApps(ctx, &ssov1.ListAppsRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.DeleteApp(ctx, &ssov1.DeleteAppRequest{AppId: appID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.AddAppMember(ctx, &ssov1.AddAppMemberRequest{AppId: membersOnlyAppID, UserId: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestApps_UpdateKeepsUnsetFields(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminID, token := adminLogin(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	createResp, err := st.AdminClient.CreateApp(ctx, &ssov1.CreateAppRequest{
+		Name:         "tests-app-" + gofakeit.UUID(),
+		RedirectUris: []string{"https://example.com/callback"},
+		GrantTypes:   []string{"authorization_code"},
+		Scopes:       []string{"orders:read"},
+	})
+	require.NoError(t, err)
+	app := createResp.GetApp()
+	t.Cleanup(func() {
+		_, _ = st.AdminClient.DeleteApp(ctx, &ssov1.DeleteAppRequest{AppId: app.GetId()})
+	})
+	assert.Equal(t, adminID, app.GetOwnerId())
+
+	name := "tests-app-" + gofakeit.UUID()
+	updateResp, err := st.AdminClient.UpdateApp(ctx, &ssov1.UpdateAppRequest{AppId: app.GetId(), Name: name})
+	require.NoError(t, err)
+	assert.Equal(t, name, updateResp.GetApp().GetName())
+	assert.Equal(t, app.GetRedirectUris(), updateResp.GetApp().GetRedirectUris())
+	assert.Equal(t, app.GetGrantTypes(), updateResp.GetApp().GetGrantTypes())
+	assert.Equal(t, app.GetScopes(), updateResp.GetApp().GetScopes())
+
+	updateResp, err = st.AdminClient.UpdateApp(ctx, &ssov1.UpdateAppRequest{
+		AppId:      app.GetId(),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"scopes"}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, updateResp.GetApp().GetScopes())
+	assert.Equal(t, name, updateResp.GetApp().GetName())
+	assert.Equal(t, app.GetRedirectUris(), updateResp.GetApp().GetRedirectUris())
+}
+
+func TestApps_InvalidRedirectURI(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, token := adminLogin(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	for _, uri := range []string{"javascript:alert(1)", "https://example.com/callback#fragment", "http://example.com/callback"} {
+		t.Run(uri, func(t *testing.T) {
+			_, err := st.AdminClient.CreateApp(ctx, &ssov1.CreateAppRequest{
+				Name:         "tests-app-" + gofakeit.UUID(),
+				RedirectUris: []string{uri},
+			})
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestApps_DisabledAppTokensAreRejected(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	createResp, err := st.AdminClient.CreateApp(adminCtx, &ssov1.CreateAppRequest{Name: "tests-app-" + gofakeit.UUID()})
+	require.NoError(t, err)
+	appId := createResp.GetApp().GetId()
+	t.Cleanup(func() {
+		_, _ = st.AdminClient.DeleteApp(adminCtx, &ssov1.DeleteAppRequest{AppId: appId})
+	})
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	registerResp, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+
+	loginResp, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appId})
+	require.NoError(t, err)
+	userCtx := withToken(ctx, loginResp.GetToken())
+
+	_, err = st.AuthClient.ListSessions(userCtx, &ssov1.ListSessionsRequest{UserId: registerResp.GetUserId()})
+	require.NoError(t, err)
+
+	_, err = st.AdminClient.UpdateApp(adminCtx, &ssov1.UpdateAppRequest{AppId: appId, Disabled: true})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.ListSessions(userCtx, &ssov1.ListSessionsRequest{UserId: registerResp.GetUserId()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestApps_Members(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	createResp, err := st.AdminClient.CreateApp(adminCtx, &ssov1.CreateAppRequest{
+		Name:               "tests-app-" + gofakeit.UUID(),
+		MembershipRequired: true,
+	})
+	require.NoError(t, err)
+	appId := createResp.GetApp().GetId()
+	t.Cleanup(func() {
+		_, _ = st.AdminClient.DeleteApp(adminCtx, &ssov1.DeleteAppRequest{AppId: appId})
+	})
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	registerResp, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+	userID := registerResp.GetUserId()
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appId})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	addResp, err := st.AdminClient.AddAppMember(adminCtx, &ssov1.AddAppMemberRequest{AppId: appId, UserId: userID})
+	require.NoError(t, err)
+	assert.True(t, addResp.GetAdded())
+
+	addResp, err = st.AdminClient.AddAppMember(adminCtx, &ssov1.AddAppMemberRequest{AppId: appId, UserId: userID})
+	require.NoError(t, err)
+	assert.False(t, addResp.GetAdded())
+
+	listResp, err := st.AdminClient.ListAppMembers(adminCtx, &ssov1.ListAppMembersRequest{AppId: appId})
+	require.NoError(t, err)
+	require.Len(t, listResp.GetMembers(), 1)
+	assert.Equal(t, userID, listResp.GetMembers()[0].GetUserId())
+	assert.Equal(t, email, listResp.GetMembers()[0].GetEmail())
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appId})
+	require.NoError(t, err)
+
+	removeResp, err := st.AdminClient.RemoveAppMember(adminCtx, &ssov1.RemoveAppMemberRequest{AppId: appId, UserId: userID})
+	require.NoError(t, err)
+	assert.True(t, removeResp.GetRemoved())
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appId})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	listResp, err = st.AdminClient.ListAppMembers(adminCtx, &ssov1.ListAppMembersRequest{AppId: appId})
+	require.NoError(t, err)
+	assert.Empty(t, listResp.GetMembers())
+}
+
+func TestApps_MembersOfUnknownAppOrUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, token := adminLogin(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	userID, _ := newUser(ctx, t, st)
+
+	_, err := st.AdminClient.AddAppMember(ctx, &ssov1.AddAppMemberRequest{AppId: math.MaxInt32, UserId: userID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AdminClient.AddAppMember(ctx, &ssov1.AddAppMemberRequest{AppId: membersOnlyAppID, UserId: math.MaxInt32})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AdminClient.ListAppMembers(ctx, &ssov1.ListAppMembersRequest{AppId: math.MaxInt32})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestApps_AppScopedPermissionIsDenied(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	userID, token := newUser(ctx, t, st)
+	userCtx := withToken(ctx, token)
+
+	// admin role granted in the test app only carries apps:manage within it
+	_, err := st.AdminClient.GrantRole(adminCtx, &ssov1.GrantRoleRequest{UserId: userID, Role: "admin", AppId: appID})
+	require.NoError(t, err)
+
+	_, err = st.AdminClient.CreateApp(userCtx, &ssov1.CreateAppRequest{
+		Name:         "tests-app-" + gofakeit.UUID(),
+		RedirectUris: []string{"https://example.com/callback"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.RotateAppSecret(userCtx, &ssov1.RotateAppSecretRequest{AppId: membersOnlyAppID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.AddAppMember(userCtx, &ssov1.AddAppMemberRequest{AppId: membersOnlyAppID, UserId: userID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -7,7 +7,7 @@ import (
 	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
 	"github.com/4aykovski/grpc_auth_sso/tests/suite"
 	"github.com/brianvoe/gofakeit"
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,7 +15,6 @@ import (
 const (
 	emptyAppID = 0
 	appID      = 1
-	appSecret  = "my_secret_app1_key_for_tests"
 
 	passDefaultLen = 10
 )
@@ -45,13 +44,15 @@ func TestRegisterLogin_Login_HappyPath(t *testing.T) {
 	token := loginResp.GetToken()
 	require.NotEmpty(t, token)
 
-	tokenParsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(appSecret), nil
-	})
+	// token is accepted by the service, its signature is checked against published keys in TestOAuth_JWKS
+	_, err = st.AuthClient.ListSessions(withToken(ctx, token), &ssov1.ListSessionsRequest{UserId: registerResp.GetUserId()})
 	require.NoError(t, err)
 
-	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
-	assert.True(t, ok)
+	claims := jwt.MapClaims{}
+	tokenParsed, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+	assert.NotEqual(t, jwt.SigningMethodHS256.Alg(), tokenParsed.Method.Alg())
+	assert.NotEmpty(t, tokenParsed.Header["kid"])
 
 	assert.Equal(t, int(claims["app_id"].(float64)), appID)
 	assert.Equal(t, int64(claims["user_id"].(float64)), registerResp.GetUserId())
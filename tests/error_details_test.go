@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/internal/adapters/grpc/apierror"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorDetails_BadRequest(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    "not-an-email",
+		Password: "",
+		AppId:    appID,
+	})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+
+	var fields []string
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range d.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"email", "password"}, fields)
+	assert.Equal(t, apierror.ReasonInvalidArgument, errorReason(s))
+}
+
+func TestErrorDetails_InvalidCredentials(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+		AppId:    appID,
+	})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+	assert.Equal(t, apierror.ReasonInvalidCredentials, errorReason(s))
+}
+
+func TestErrorDetails_LocalizedMessage(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "accept-language", "ru-RU,ru;q=0.9")
+
+	_, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+		AppId:    appID,
+	})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	assert.Equal(t, "invalid credentials", s.Message())
+
+	var localized *errdetails.LocalizedMessage
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.LocalizedMessage); ok {
+			localized = d
+		}
+	}
+	require.NotNil(t, localized)
+	assert.Equal(t, "ru", localized.GetLocale())
+	assert.Equal(t, "неверный email или пароль", localized.GetMessage())
+}
+
+func errorReason(s *status.Status) string {
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.ErrorInfo); ok {
+			return d.GetReason()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway_RegisterLogin(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.Gateway.Enabled {
+		t.Skip("gateway is disabled")
+	}
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	resp := gatewayPost(ctx, t, st, "/v1/register", map[string]any{
+		"email":    email,
+		"password": password,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("X-Request-Id"))
+
+	resp = gatewayPost(ctx, t, st, "/v1/login", map[string]any{
+		"email":    email,
+		"password": password,
+		"appId":    appID,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NotEmpty(t, body.Token)
+}
+
+func TestGateway_ErrorMapping(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.Gateway.Enabled {
+		t.Skip("gateway is disabled")
+	}
+
+	resp := gatewayPost(ctx, t, st, "/v1/register", map[string]any{
+		"email":    "",
+		"password": randomFakePassword(),
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = gatewayPost(ctx, t, st, "/v1/login", map[string]any{
+		"email":    gofakeit.Email(),
+		"password": randomFakePassword(),
+		"appId":    appID,
+	})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestGateway_OpenAPI(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.Gateway.Enabled {
+		t.Skip("gateway is disabled")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gatewayURL(st, "/openapi.json"), nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var spec struct {
+		Paths map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Contains(t, spec.Paths, "/v1/login")
+	assert.Contains(t, spec.Paths, "/v1/register")
+}
+
+func gatewayPost(ctx context.Context, t *testing.T, st *suite.Suite, path string, body any) *http.Response {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gatewayURL(st, path), bytes.NewReader(b))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func gatewayURL(st *suite.Suite, path string) string {
+	return fmt.Sprintf("http://%s%s", net.JoinHostPort(st.Cfg.Gateway.Host, strconv.Itoa(st.Cfg.Gateway.Port)), path)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// admin seeded by test migrations, it has admin role granted globally
+const (
+	adminEmail    = "admin@sso.test"
+	adminPassword = "test-admin-Passw0rd"
+)
+
+// adminsMu serializes tests changing the set of global admins,
+// so revoking admin role of seeded admin reliably leaves no admins
+var adminsMu sync.Mutex
+
+// withToken returns a copy of ctx sending access token in authorization metadata
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// newUser registers user, logs in to the test app and returns user id with access token
+func newUser(ctx context.Context, t *testing.T, st *suite.Suite) (int64, string) {
+	t.Helper()
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	registerResp, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(t, err)
+
+	loginResp, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    email,
+		Password: password,
+		AppId:    appID,
+	})
+	require.NoError(t, err)
+
+	return registerResp.GetUserId(), loginResp.GetToken()
+}
+
+// adminLogin logs seeded admin in to the test app and returns its user id with access token
+func adminLogin(ctx context.Context, t *testing.T, st *suite.Suite) (int64, string) {
+	t.Helper()
+
+	loginResp, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    adminEmail,
+		Password: adminPassword,
+		AppId:    appID,
+	})
+	require.NoError(t, err)
+
+	token := loginResp.GetToken()
+
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+
+	userID, ok := claims["user_id"].(float64)
+	require.True(t, ok)
+
+	return int64(userID), token
+}
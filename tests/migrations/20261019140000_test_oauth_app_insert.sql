@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO apps (id, name, client_id, redirect_uris, grant_types)
+VALUES (3, 'test-oauth', 'test-oauth-client', '{http://localhost:3000/callback}', '{authorization_code}')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
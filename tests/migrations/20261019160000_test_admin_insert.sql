@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- password is test-admin-Passw0rd
+INSERT INTO users (email, password)
+VALUES ('admin@sso.test', '$2a$10$ihRPnI0/f.pZQGT9K4O6uOt3t0UKYSscel/jzv372AHhQXkAxxHHS')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u CROSS JOIN roles r WHERE u.email = 'admin@sso.test' AND r.name = 'admin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
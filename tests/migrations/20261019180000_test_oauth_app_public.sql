@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE apps SET public = true WHERE id = 3;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
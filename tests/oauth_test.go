@@ -0,0 +1,420 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/pkg/manager/token"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	oauthClientID    = "test-oauth-client"
+	oauthRedirectURI = "http://localhost:3000/callback"
+)
+
+var csrfTokenPattern = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+func TestOAuth_AuthorizationCode(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	client := oauthClient(t)
+	code := authorizationCode(ctx, t, st, client, oauthClientID, email, password, challenge)
+
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {oauthClientID},
+		"code":          {code},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {strings.Repeat("w", 43)},
+	}
+
+	resp := oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), exchange)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_grant", oauthError(t, resp))
+
+	// the code is consumed by the failed attempt
+	exchange.Set("code_verifier", verifier)
+	resp = oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), exchange)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_grant", oauthError(t, resp))
+}
+
+func TestOAuth_ConfidentialClientRequiresSecret(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	createResp, err := st.AdminClient.CreateApp(adminCtx, &ssov1.CreateAppRequest{
+		Name:         "tests-app-" + gofakeit.UUID(),
+		RedirectUris: []string{oauthRedirectURI},
+		GrantTypes:   []string{"authorization_code"},
+	})
+	require.NoError(t, err)
+	app := createResp.GetApp()
+	require.False(t, app.GetPublic())
+	require.NotEmpty(t, createResp.GetClientSecret())
+	t.Cleanup(func() {
+		_, _ = st.AdminClient.DeleteApp(adminCtx, &ssov1.DeleteAppRequest{AppId: app.GetId()})
+	})
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+	_, err = st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	client := oauthClient(t)
+	code := authorizationCode(ctx, t, st, client, app.GetClientId(), email, password, challenge)
+
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {app.GetClientId()},
+		"code":          {code},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {verifier},
+	}
+
+	resp := oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), exchange)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "invalid_client", oauthError(t, resp))
+
+	// the code isn't consumed by unauthenticated client
+	exchange.Set("client_secret", createResp.GetClientSecret())
+	resp = oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), exchange)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, err = st.AdminClient.RotateAppSecret(adminCtx, &ssov1.RotateAppSecretRequest{AppId: app.GetId()})
+	require.NoError(t, err)
+
+	updateResp, err := st.AdminClient.UpdateApp(adminCtx, &ssov1.UpdateAppRequest{AppId: app.GetId(), Public: true})
+	require.NoError(t, err)
+	assert.True(t, updateResp.GetApp().GetPublic())
+
+	_, err = st.AdminClient.RotateAppSecret(adminCtx, &ssov1.RotateAppSecretRequest{AppId: app.GetId()})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// authorizationCode logs user in through authorize page of client and returns issued authorization code
+func authorizationCode(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	client *http.Client,
+	clientID string,
+	email string,
+	password string,
+	challenge string,
+) string {
+	t.Helper()
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {oauthRedirectURI},
+		"state":                 {"xyz"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	resp := oauthDo(ctx, t, client, http.MethodGet, oauthURL(st, "/authorize?"+params.Encode()), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	match := csrfTokenPattern.FindSubmatch(page)
+	require.Len(t, match, 2)
+
+	form := url.Values{
+		"csrf_token": {string(match[1])},
+		"email":      {email},
+		"password":   {password},
+	}
+	for key, values := range params {
+		form[key] = values
+	}
+
+	resp = oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/authorize"), form)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	return code
+}
+
+func TestOAuth_InvalidClient(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	client := oauthClient(t)
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"unknown-client"},
+		"redirect_uri":          {oauthRedirectURI},
+		"code_challenge":        {strings.Repeat("c", 43)},
+		"code_challenge_method": {"S256"},
+	}
+	resp := oauthDo(ctx, t, client, http.MethodGet, oauthURL(st, "/authorize?"+params.Encode()), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	params.Set("client_id", oauthClientID)
+	params.Set("redirect_uri", "http://evil.example.com/callback")
+	resp = oauthDo(ctx, t, client, http.MethodGet, oauthURL(st, "/authorize?"+params.Encode()), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Location"))
+
+	params.Set("redirect_uri", oauthRedirectURI)
+	params.Set("code_challenge_method", "plain")
+	resp = oauthDo(ctx, t, client, http.MethodGet, oauthURL(st, "/authorize?"+params.Encode()), nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", location.Query().Get("error"))
+
+	resp = oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), url.Values{
+		"grant_type": {"password"},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "unsupported_grant_type", oauthError(t, resp))
+}
+
+func TestOAuth_ClientCredentials_InvalidClient(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	client := oauthClient(t)
+
+	resp := oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), url.Values{
+		"grant_type": {"client_credentials"},
+		"client_id":  {oauthClientID},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_request", oauthError(t, resp))
+
+	resp = oauthDo(ctx, t, client, http.MethodPost, oauthURL(st, "/token"), url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"unknown-client"},
+		"client_secret": {"secret"},
+	})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "invalid_client", oauthError(t, resp))
+}
+
+func TestOAuth_ClientCredentials_AppPrincipal(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	createResp, err := st.AdminClient.CreateApp(adminCtx, &ssov1.CreateAppRequest{
+		Name:       "tests-app-" + gofakeit.UUID(),
+		GrantTypes: []string{"client_credentials"},
+		Scopes:     []string{"sso:authorize"},
+	})
+	require.NoError(t, err)
+	app := createResp.GetApp()
+	t.Cleanup(func() {
+		_, _ = st.AdminClient.DeleteApp(adminCtx, &ssov1.DeleteAppRequest{AppId: app.GetId()})
+	})
+
+	resp := oauthDo(ctx, t, http.DefaultClient, http.MethodPost, oauthURL(st, "/token"), url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {app.GetClientId()},
+		"client_secret": {createResp.GetClientSecret()},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		Scope       string `json:"scope"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	assert.Equal(t, "sso:authorize", tokens.Scope)
+	appCtx := withToken(ctx, tokens.AccessToken)
+
+	userID, _ := newUser(ctx, t, st)
+
+	checkResp, err := st.AuthClient.CheckPermission(appCtx, &ssov1.CheckPermissionRequest{
+		UserId:     userID,
+		Permission: "orders:read",
+		AppId:      app.GetId(),
+	})
+	require.NoError(t, err)
+	assert.False(t, checkResp.GetAllowed())
+
+	// apps act only in themselves and never on behalf of users
+	_, err = st.AuthClient.CheckPermission(appCtx, &ssov1.CheckPermissionRequest{
+		UserId:     userID,
+		Permission: "orders:read",
+		AppId:      appID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.ListSessions(appCtx, &ssov1.ListSessionsRequest{UserId: userID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// oauthClient returns HTTP client keeping cookies and not following redirects
+func oauthClient(t *testing.T) *http.Client {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func oauthDo(ctx context.Context, t *testing.T, client *http.Client, method string, target string, form url.Values) *http.Response {
+	t.Helper()
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	require.NoError(t, err)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func oauthError(t *testing.T, resp *http.Response) string {
+	t.Helper()
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	return body.Error
+}
+
+func oauthURL(st *suite.Suite, path string) string {
+	return fmt.Sprintf("http://%s%s", net.JoinHostPort(st.Cfg.OAuth.Host, strconv.Itoa(st.Cfg.OAuth.Port)), path)
+}
+
+func TestOAuth_JWKS(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.OAuth.Enabled {
+		t.Skip("oauth is disabled")
+	}
+
+	_, accessToken := newUser(ctx, t, st)
+
+	resp := oauthDo(ctx, t, http.DefaultClient, http.MethodGet, oauthURL(st, "/.well-known/jwks.json"), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var jwks token.JWKS
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+	require.NotEmpty(t, jwks.Keys)
+
+	parsed, err := jwt.Parse(accessToken, func(t *jwt.Token) (any, error) {
+		for _, key := range jwks.Keys {
+			if key.Kid == t.Header["kid"] {
+				return publicKey(key)
+			}
+		}
+
+		return nil, errors.New("unknown key id")
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodES256.Alg()}))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+}
+
+// publicKey decodes Ed25519 or P-256 ECDSA public key of JWK
+func publicKey(jwk token.JWK) (any, error) {
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+
+	switch jwk.Kty {
+	case "OKP":
+		return ed25519.PublicKey(x), nil
+	case "EC":
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorize_NoPolicy(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	resp, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{
+		UserId:   userID,
+		AppId:    appID,
+		Action:   "tests:unknown-action",
+		Resource: map[string]string{"owner_id": "1"},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.GetAllowed())
+	assert.False(t, resp.GetApplicable())
+	assert.Empty(t, resp.GetPolicy())
+}
+
+func TestPolicies_RequirePermission(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	_, err := st.AdminClient.PutPolicy(ctx, &ssov1.PutPolicyRequest{
+		Name:       "tests-owner",
+		Action:     "apps:manage",
+		Effect:     "allow",
+		Expression: `resource.owner_id == string(user.id)`,
+		Enabled:    true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.ListPolicies(ctx, &ssov1.ListPoliciesRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestPolicies_AppScopedPermissionIsDenied(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, adminToken := adminLogin(ctx, t, st)
+	adminCtx := withToken(ctx, adminToken)
+
+	userID, token := newUser(ctx, t, st)
+	userCtx := withToken(ctx, token)
+
+	// admin role granted in the test app only carries policies:manage within it
+	_, err := st.AdminClient.GrantRole(adminCtx, &ssov1.GrantRoleRequest{UserId: userID, Role: "admin", AppId: appID})
+	require.NoError(t, err)
+
+	_, err = st.AdminClient.PutPolicy(userCtx, &ssov1.PutPolicyRequest{
+		Name:       "tests-deny-all",
+		Action:     "login",
+		Effect:     "deny",
+		Expression: `true`,
+		Enabled:    true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.ListPolicies(userCtx, &ssov1.ListPoliciesRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
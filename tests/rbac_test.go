@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// membersOnlyAppID is an app seeded with membership required
+const membersOnlyAppID = 2
+
+func TestRBAC_NewUserHasNoRoles(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	rolesResp, err := st.AuthClient.ListUserRoles(ctx, &ssov1.ListUserRolesRequest{
+		UserId: userID,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, rolesResp.GetRoles())
+
+	checkResp, err := st.AuthClient.CheckPermission(ctx, &ssov1.CheckPermissionRequest{
+		UserId:     userID,
+		Permission: "admin",
+		AppId:      appID,
+	})
+	require.NoError(t, err)
+	assert.False(t, checkResp.GetAllowed())
+}
+
+func TestRBAC_CheckPermission_FailCases(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID, token := newUser(ctx, t, st)
+	ctx = withToken(ctx, token)
+
+	tests := []struct {
+		name       string
+		userID     int64
+		permission string
+		appID      int32
+		code       codes.Code
+	}{
+		{name: "Empty permission", userID: userID, permission: "", appID: appID, code: codes.InvalidArgument},
+		// only admins may check permissions of other users
+		{name: "Empty user id", userID: 0, permission: "admin", appID: appID, code: codes.PermissionDenied},
+		{name: "Another user", userID: userID + 1, permission: "admin", appID: appID, code: codes.PermissionDenied},
+		{name: "Empty app id", userID: userID, permission: "admin", appID: emptyAppID, code: codes.InvalidArgument},
+		{name: "Unknown app", userID: userID, permission: "admin", appID: 1 << 30, code: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := st.AuthClient.CheckPermission(ctx, &ssov1.CheckPermissionRequest{
+				UserId:     tt.userID,
+				Permission: tt.permission,
+				AppId:      tt.appID,
+			})
+			require.Error(t, err)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestLogin_MembershipRequired(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    email,
+		Password: password,
+		AppId:    membersOnlyAppID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Email:    email,
+		Password: password,
+		AppId:    appID,
+	})
+	require.NoError(t, err)
+}
+
+func TestRBAC_RequiresToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, token := newUser(ctx, t, st)
+
+	_, err = st.AuthClient.IsAdmin(withToken(ctx, token), &ssov1.IsAdminRequest{UserId: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestReflection_NotServedOnMainListener(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := listServices(ctx, t, suite.GRPCAddress(st.Cfg))
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestReflection_RequiresAdmin(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.Debug.Enabled {
+		t.Skip("debugging tools are disabled")
+	}
+
+	address := debugListenerAddress(st)
+	if address == "" {
+		t.Skip("no gRPC listener exposes debug service")
+	}
+
+	_, err := listServices(ctx, t, address)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, token := newUser(ctx, t, st)
+
+	_, err = listServices(withToken(ctx, token), t, address)
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// listServices returns services listed by gRPC reflection of server on address
+func listServices(ctx context.Context, t *testing.T, address string) ([]string, error) {
+	t.Helper()
+
+	cc, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+
+	stream, err := reflectionpb.NewServerReflectionClient(cc).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+
+	return services, nil
+}
+
+// debugListenerAddress returns address of tcp listener exposing debug service, empty if there is none
+func debugListenerAddress(st *suite.Suite) string {
+	for _, l := range st.Cfg.GRPC.Listeners {
+		if (l.Network == "" || l.Network == "tcp") && !l.TLS && slices.Contains(l.Services, "debug") {
+			return l.Address
+		}
+	}
+
+	return ""
+}
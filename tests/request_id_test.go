@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const requestIDHeader = "x-request-id"
+
+func TestRequestID_Echoed(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	const requestID = "test-request-id"
+	ctx = metadata.AppendToOutgoingContext(ctx, requestIDHeader, requestID)
+
+	var header metadata.MD
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{requestID}, header.Get(requestIDHeader))
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	var header metadata.MD
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	require.Len(t, header.Get(requestIDHeader), 1)
+	assert.NotEmpty(t, header.Get(requestIDHeader)[0])
+}
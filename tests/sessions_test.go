@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSessions_ListRevoke_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	password := randomFakePassword()
+
+	registerResp, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(t, err)
+	userID := registerResp.GetUserId()
+
+	var (
+		sessionIDs []int64
+		token      string
+	)
+	for i := 0; i < 2; i++ {
+		loginResp, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+			Email:    email,
+			Password: password,
+			AppId:    appID,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, loginResp.GetRefreshToken())
+		require.NotEmpty(t, loginResp.GetSessionId())
+
+		sessionIDs = append(sessionIDs, loginResp.GetSessionId())
+		token = loginResp.GetToken()
+	}
+
+	userCtx := withToken(ctx, token)
+
+	listResp, err := st.AuthClient.ListSessions(userCtx, &ssov1.ListSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	require.Len(t, listResp.GetSessions(), 2)
+	for _, session := range listResp.GetSessions() {
+		assert.Equal(t, userID, session.GetUserId())
+		assert.Equal(t, int32(appID), session.GetAppId())
+		assert.NotEmpty(t, session.GetIp())
+	}
+
+	_, err = st.AuthClient.RevokeSession(userCtx, &ssov1.RevokeSessionRequest{
+		UserId:    userID,
+		SessionId: sessionIDs[0],
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.RevokeSession(userCtx, &ssov1.RevokeSessionRequest{
+		UserId:    userID,
+		SessionId: sessionIDs[0],
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	listResp, err = st.AuthClient.ListSessions(userCtx, &ssov1.ListSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	require.Len(t, listResp.GetSessions(), 1)
+	assert.Equal(t, sessionIDs[1], listResp.GetSessions()[0].GetId())
+
+	revokeAllResp, err := st.AuthClient.RevokeAllSessions(userCtx, &ssov1.RevokeAllSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revokeAllResp.GetRevoked())
+
+	// the access token belonged to a revoked session
+	_, err = st.AuthClient.ListSessions(userCtx, &ssov1.ListSessionsRequest{UserId: userID})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	loginResp, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appID})
+	require.NoError(t, err)
+
+	listResp, err = st.AuthClient.ListSessions(withToken(ctx, loginResp.GetToken()), &ssov1.ListSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.Len(t, listResp.GetSessions(), 1)
+}
@@ -10,12 +10,15 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Suite struct {
 	*testing.T
-	Cfg        *config.Config
-	AuthClient ssov1.AuthClient
+	Cfg          *config.Config
+	AuthClient   ssov1.AuthClient
+	AdminClient  ssov1.AdminClient
+	HealthClient healthpb.HealthClient
 }
 
 func New(t *testing.T) (context.Context, *Suite) {
@@ -31,19 +34,22 @@ func New(t *testing.T) (context.Context, *Suite) {
 		cancelCtx()
 	})
 
-	cc, err := grpc.NewClient(grpcAddress(cfg),
+	cc, err := grpc.NewClient(GRPCAddress(cfg),
 		grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	return ctx, &Suite{
-		T:          t,
-		Cfg:        cfg,
-		AuthClient: ssov1.NewAuthClient(cc),
+		T:            t,
+		Cfg:          cfg,
+		AuthClient:   ssov1.NewAuthClient(cc),
+		AdminClient:  ssov1.NewAdminClient(cc),
+		HealthClient: healthpb.NewHealthClient(cc),
 	}
 }
 
-func grpcAddress(cfg *config.Config) string {
+// GRPCAddress returns address of main gRPC listener
+func GRPCAddress(cfg *config.Config) string {
 	return net.JoinHostPort(cfg.GRPC.Host, strconv.Itoa(cfg.GRPC.Port))
 }
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"connectrpc.com/connect"
+	ssov1 "github.com/4aykovski/grpc_auth_protos/gen/go/sso"
+	"github.com/4aykovski/grpc_auth_protos/gen/go/sso/ssov1connect"
+	"github.com/4aykovski/grpc_auth_sso/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeb_RegisterLogin(t *testing.T) {
+	ctx, st := suite.New(t)
+	if !st.Cfg.Web.Enabled {
+		t.Skip("web listener is disabled")
+	}
+
+	tests := []struct {
+		name string
+		opts []connect.ClientOption
+	}{
+		{name: "Connect", opts: nil},
+		{name: "gRPC-Web", opts: []connect.ClientOption{connect.WithGRPCWeb()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := ssov1connect.NewAuthClient(http.DefaultClient, webURL(st), tt.opts...)
+
+			email := gofakeit.Email()
+			password := randomFakePassword()
+
+			registerResp, err := client.Register(ctx, connect.NewRequest(&ssov1.RegisterRequest{
+				Email:    email,
+				Password: password,
+			}))
+			require.NoError(t, err)
+			assert.NotEmpty(t, registerResp.Msg.GetUserId())
+			assert.NotEmpty(t, registerResp.Header().Get("X-Request-Id"))
+
+			loginResp, err := client.Login(ctx, connect.NewRequest(&ssov1.LoginRequest{
+				Email:    email,
+				Password: password,
+				AppId:    appID,
+			}))
+			require.NoError(t, err)
+			assert.NotEmpty(t, loginResp.Msg.GetToken())
+
+			_, err = client.Login(ctx, connect.NewRequest(&ssov1.LoginRequest{
+				Email:    email,
+				Password: randomFakePassword(),
+				AppId:    appID,
+			}))
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+		})
+	}
+}
+
+func webURL(st *suite.Suite) string {
+	return fmt.Sprintf("http://%s", net.JoinHostPort(st.Cfg.Web.Host, strconv.Itoa(st.Cfg.Web.Port)))
+}